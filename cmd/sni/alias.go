@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage alternative names of snippets",
+	Long: `Aliases are alternative names that can be used wherever a snippet is read,
+such as 'sni use' or 'sni edit'. They must follow the naming rules and may not
+collide with snippet names or other aliases.`,
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:               "add <name> <alias>...",
+	Short:             "Add aliases to a snippet",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeSnippetNames(1, false),
+	Run: func(cmd *cobra.Command, args []string) {
+		updateAliases(args[0], func(aliases []string) []string {
+			for _, alias := range args[1:] {
+				if !containsName(aliases, alias) {
+					aliases = append(aliases, alias)
+				}
+			}
+			return aliases
+		})
+	},
+}
+
+var aliasRmCmd = &cobra.Command{
+	Use:               "rm <name> <alias>...",
+	Short:             "Remove aliases from a snippet",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeSnippetNames(1, false),
+	Run: func(cmd *cobra.Command, args []string) {
+		updateAliases(args[0], func(aliases []string) []string {
+			var remaining []string
+			for _, alias := range aliases {
+				if !containsName(args[1:], alias) {
+					remaining = append(remaining, alias)
+				}
+			}
+			return remaining
+		})
+	},
+}
+
+// updateAliases changes the aliases of a snippet and reports the result
+func updateAliases(name string, change func([]string) []string) {
+	svc, err := snippet.NewService()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing service: %v\n", err)
+		return
+	}
+
+	existing, err := svc.GetSnippet(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting snippet: %v\n", err)
+		return
+	}
+
+	aliases := change(append([]string(nil), existing.Aliases...))
+	if err := svc.SetAliases(name, aliases); err != nil {
+		fmt.Fprintf(os.Stderr, "Error updating snippet: %v\n", err)
+		return
+	}
+
+	if len(aliases) == 0 {
+		fmt.Printf("✅ Snippet '%s' has no aliases now\n", name)
+		return
+	}
+	fmt.Printf("✅ Aliases of snippet '%s': %s\n", name, strings.Join(aliases, ", "))
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasAddCmd)
+	aliasCmd.AddCommand(aliasRmCmd)
+}
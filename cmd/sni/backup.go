@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/cli"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "List and restore backups of snippets.yaml",
+	Long: `A backup of snippets.yaml is taken in <config dir>/backups before every save.
+Retention is configured in config.yaml:
+
+  backup:
+    keep: 20          # number of backups kept (0 disables backups)
+    max_age_days: 30  # remove older backups (0 keeps them regardless of age)`,
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List backups, newest first",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		colorEnabled, _ := cmd.Flags().GetBool("color")
+		cli.EnableColors(colorEnabled)
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error initializing service: %v", err)))
+			return
+		}
+
+		backups, err := svc.ListBackups()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error listing backups: %v", err)))
+			return
+		}
+
+		if len(backups) == 0 {
+			fmt.Println(cli.ColorizeWarning("No backups found."))
+			return
+		}
+
+		fmt.Printf("📦 Found %d backup(s):\n\n", len(backups))
+		for _, b := range backups {
+			contents := "invalid"
+			if snippetsFile, err := svc.ReadBackup(b.ID); err == nil {
+				contents = fmt.Sprintf("%d snippet(s)", len(snippetsFile.Snippets))
+			}
+			fmt.Printf("%s  %s  %s  %s\n",
+				cli.NameColor.Sprint(b.ID),
+				b.Time.Format("2006-01-02 15:04:05"),
+				cli.CommandColor.Sprintf("%8s", formatSize(b.Size)),
+				contents)
+		}
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:               "restore <id>",
+	Short:             "Replace snippets.yaml with a backup",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeBackupIDs,
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error initializing service: %v\n", err)
+			return
+		}
+
+		if err := svc.RestoreBackup(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error restoring backup: %v\n", err)
+			return
+		}
+
+		fmt.Printf("✅ Backup '%s' restored! The replaced file was backed up as well.\n", args[0])
+	},
+}
+
+// formatSize formats a byte count for display
+func formatSize(size int64) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.1f KB", float64(size)/1024)
+}
+
+// completeBackupIDs completes backup IDs
+func completeBackupIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	svc, err := snippet.NewService()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	backups, err := svc.ListBackups()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, b := range backups {
+		if strings.HasPrefix(b.ID, toComplete) {
+			completions = append(completions, b.ID)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	backupListCmd.Flags().Bool("color", false, "Enable colorized output")
+
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+}
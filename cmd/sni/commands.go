@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -9,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/atobaum/snippet-manager/internal/cli"
+	"github.com/atobaum/snippet-manager/internal/config"
 	"github.com/atobaum/snippet-manager/internal/selector"
 	"github.com/atobaum/snippet-manager/internal/server"
 	"github.com/atobaum/snippet-manager/internal/snippet"
@@ -28,6 +30,14 @@ var newCmd = &cobra.Command{
 			return
 		}
 
+		if allowAnyName, _ := cmd.Flags().GetBool("allow-any-name"); allowAnyName {
+			svc.SetAllowAnyName(true)
+		}
+		if err := svc.CheckName(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+
 		// Interactive input
 		reader := bufio.NewReader(os.Stdin)
 
@@ -35,7 +45,7 @@ var newCmd = &cobra.Command{
 		description, _ := reader.ReadString('\n')
 		description = strings.TrimSpace(description)
 
-		fmt.Print("Language (e.g., bash, go, python, javascript): ")
+		fmt.Print("Language (e.g., bash, go, python, javascript; blank to detect): ")
 		language, _ := reader.ReadString('\n')
 		language = strings.TrimSpace(language)
 
@@ -62,7 +72,24 @@ var newCmd = &cobra.Command{
 		command := strings.Join(commandLines, "")
 		command = strings.TrimSpace(command)
 
-		if err := svc.CreateSnippet(name, description, command, language, tags); err != nil {
+		newSnippet := snippet.NewSnippet(name, description, command, language, tags)
+		newSnippet.Secret, _ = cmd.Flags().GetBool("secret")
+		newSnippet.Aliases, _ = cmd.Flags().GetStringArray("alias")
+		attachments, _ := cmd.Flags().GetStringArray("attach")
+		if newSnippet.Files, err = readBundleFiles(attachments); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+
+		// Fill in what was left blank from the content
+		newSnippet.ApplyDefaults()
+		if language == "" && newSnippet.Language != "" {
+			fmt.Printf("Detected language: %s\n", newSnippet.Language)
+		}
+		if len(tags) == 0 && len(newSnippet.Tags) > 0 {
+			fmt.Printf("Suggested tags: %s\n", strings.Join(newSnippet.Tags, ", "))
+		}
+		if err := svc.AddSnippet(newSnippet); err != nil {
 			fmt.Fprintf(os.Stderr, "Error creating snippet: %v\n", err)
 			return
 		}
@@ -72,8 +99,10 @@ var newCmd = &cobra.Command{
 }
 
 var listCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all snippets",
+	Use:               "list [namespace/]",
+	Short:             "List all snippets, optionally within a namespace",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeSnippetNames(1, true),
 	Run: func(cmd *cobra.Command, args []string) {
 		colorEnabled, _ := cmd.Flags().GetBool("color")
 		cli.EnableColors(colorEnabled)
@@ -84,7 +113,12 @@ var listCmd = &cobra.Command{
 			return
 		}
 
-		snippets, err := svc.ListSnippets()
+		filter := snippetFilter(cmd)
+		if len(args) > 0 {
+			filter.Namespace = strings.Trim(args[0], snippet.NamespaceSeparator)
+		}
+
+		snippets, err := svc.FilterSnippets(filter)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error listing snippets: %v", err)))
 			return
@@ -97,10 +131,17 @@ var listCmd = &cobra.Command{
 
 		fmt.Printf("%s\n\n", cli.ColorizeTitle(fmt.Sprintf("Found %d snippet(s):", len(snippets))))
 		for _, s := range snippets {
-			fmt.Println(cli.ColorizeSnippetName(s.Name))
+			if s.Pinned {
+				fmt.Println(cli.ColorizePinnedName(s.Name))
+			} else {
+				fmt.Println(cli.ColorizeSnippetName(s.Name))
+			}
 			if desc := cli.ColorizeDescription(s.Description); desc != "" {
 				fmt.Println(desc)
 			}
+			if aliases := cli.ColorizeAliases(s.Aliases); aliases != "" {
+				fmt.Println(aliases)
+			}
 			if lang := cli.ColorizeLanguage(s.Language); lang != "" {
 				fmt.Println(lang)
 			}
@@ -141,7 +182,11 @@ var searchCmd = &cobra.Command{
 
 		fmt.Printf("%s\n\n", cli.ColorizeTitle(fmt.Sprintf("🔍 Found %d snippet(s) for '%s':", len(snippets), keyword)))
 		for _, s := range snippets {
-			fmt.Println(cli.ColorizeSnippetName(s.Name))
+			if s.Pinned {
+				fmt.Println(cli.ColorizePinnedName(s.Name))
+			} else {
+				fmt.Println(cli.ColorizeSnippetName(s.Name))
+			}
 			if desc := cli.ColorizeDescription(s.Description); desc != "" {
 				fmt.Println(desc)
 			}
@@ -157,9 +202,10 @@ var searchCmd = &cobra.Command{
 }
 
 var useCmd = &cobra.Command{
-	Use:   "use <name>",
-	Short: "Output snippet content to terminal",
-	Args:  cobra.ExactArgs(1),
+	Use:               "use <name>",
+	Short:             "Output snippet content to terminal",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSnippetNames(1, false),
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
 
@@ -169,21 +215,45 @@ var useCmd = &cobra.Command{
 			return
 		}
 
-		snippet, err := svc.GetSnippet(name)
+		setValues, _ := cmd.Flags().GetStringArray("set")
+		values, err := parseSetValues(setValues)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+
+		snippet, err := svc.ComposeSnippet(name)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting snippet: %v\n", err)
 			return
 		}
 
-		// Output the command content directly
-		fmt.Print(snippet.Command)
+		if fileName, _ := cmd.Flags().GetString("file"); fileName != "" {
+			f := snippet.File(fileName)
+			if f == nil {
+				fmt.Fprintf(os.Stderr, "Error: snippet '%s' has no file '%s'\n", name, fileName)
+				return
+			}
+			snippet.Command = f.Content
+		}
+
+		opts := snippetRenderOptions(cmd, svc, values)
+		content, err := snippet.Render(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering snippet: %v\n", err)
+			return
+		}
+
+		// Output the rendered command content directly
+		fmt.Print(content)
 	},
 }
 
 var editCmd = &cobra.Command{
-	Use:   "edit <name>",
-	Short: "Edit an existing snippet",
-	Args:  cobra.ExactArgs(1),
+	Use:               "edit <name>",
+	Short:             "Edit an existing snippet",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSnippetNames(1, false),
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
 
@@ -194,7 +264,7 @@ var editCmd = &cobra.Command{
 		}
 
 		// Get existing snippet
-		existing, err := svc.GetSnippet(name)
+		existing, err := svc.RevealSnippet(name)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting snippet: %v\n", err)
 			return
@@ -258,10 +328,38 @@ var editCmd = &cobra.Command{
 	},
 }
 
+var renameCmd = &cobra.Command{
+	Use:               "rename <old> <new>",
+	Short:             "Rename a snippet",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeSnippetNames(1, false),
+	Run: func(cmd *cobra.Command, args []string) {
+		oldName, newName := args[0], args[1]
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error initializing service: %v\n", err)
+			return
+		}
+
+		if allowAnyName, _ := cmd.Flags().GetBool("allow-any-name"); allowAnyName {
+			svc.SetAllowAnyName(true)
+		}
+
+		if err := svc.RenameSnippet(oldName, newName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error renaming snippet: %v\n", err)
+			return
+		}
+
+		fmt.Printf("✅ Snippet '%s' renamed to '%s'!\n", oldName, newName)
+	},
+}
+
 var rmCmd = &cobra.Command{
-	Use:   "rm <name>",
-	Short: "Remove a snippet",
-	Args:  cobra.ExactArgs(1),
+	Use:               "rm <name>",
+	Short:             "Remove a snippet",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSnippetNames(1, false),
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
 
@@ -271,6 +369,8 @@ var rmCmd = &cobra.Command{
 			return
 		}
 
+		force, _ := cmd.Flags().GetBool("force")
+
 		// Confirm deletion
 		reader := bufio.NewReader(os.Stdin)
 		fmt.Printf("Are you sure you want to delete snippet '%s'? (y/N): ", name)
@@ -282,7 +382,11 @@ var rmCmd = &cobra.Command{
 			return
 		}
 
-		if err := svc.DeleteSnippet(name); err != nil {
+		if err := svc.DeleteSnippet(name, force); err != nil {
+			if errors.Is(err, snippet.ErrIncluded) {
+				fmt.Fprintf(os.Stderr, "Error: %v (use --force to delete anyway)\n", err)
+				return
+			}
 			fmt.Fprintf(os.Stderr, "Error deleting snippet: %v\n", err)
 			return
 		}
@@ -296,9 +400,18 @@ var serverCmd = &cobra.Command{
 	Short: "Start the web UI server",
 	Run: func(cmd *cobra.Command, args []string) {
 		devMode, _ := cmd.Flags().GetBool("dev")
+		host, _ := cmd.Flags().GetString("host")
 		port, _ := cmd.Flags().GetInt("port")
-
-		srv, err := server.NewServer(port, devMode)
+		tlsCert, _ := cmd.Flags().GetString("tls-cert")
+		tlsKey, _ := cmd.Flags().GetString("tls-key")
+
+		srv, err := server.NewServer(server.Options{
+			Host:    host,
+			Port:    port,
+			DevMode: devMode,
+			TLSCert: tlsCert,
+			TLSKey:  tlsKey,
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error creating server: %v\n", err)
 			return
@@ -311,9 +424,64 @@ var serverCmd = &cobra.Command{
 	},
 }
 
+var serverTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Show the web server's API token",
+	Long: `Show the API token used to sign in to the web UI or to call the API with
+"Authorization: Bearer <token>". The token is generated on the first server start
+and stored in <config dir>/server.token.
+
+With --read-only, show the read-only token instead, creating it if needed. It can
+only read snippets. Delete server-readonly.token to disable it.
+New and rotated tokens take effect when the server restarts.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		readOnly, _ := cmd.Flags().GetBool("read-only")
+		rotate, _ := cmd.Flags().GetBool("rotate")
+
+		cfg, err := config.DefaultConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			return
+		}
+		if err := os.MkdirAll(cfg.ConfigDir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating config directory: %v\n", err)
+			return
+		}
+
+		path := cfg.TokenFile
+		if readOnly {
+			path = cfg.ReadOnlyTokenFile
+		}
+
+		var token string
+		if rotate {
+			token, err = server.GenerateToken(path)
+		} else {
+			token, err = server.LoadToken(path, true)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading token: %v\n", err)
+			return
+		}
+
+		if rotate {
+			fmt.Fprintln(os.Stderr, "🔄 Token rotated; restart sni server to use it")
+		}
+		fmt.Println(token)
+	},
+}
+
 func init() {
+	serverTokenCmd.Flags().Bool("read-only", false, "Show the read-only token, creating it if needed")
+	serverTokenCmd.Flags().Bool("rotate", false, "Replace the token with a new one")
+	serverCmd.AddCommand(serverTokenCmd)
+
 	serverCmd.Flags().BoolP("dev", "d", false, "Run in development mode (proxy to Svelte dev server)")
+	serverCmd.Flags().String("host", "127.0.0.1", "Address to bind (use 0.0.0.0 to listen on every interface)")
 	serverCmd.Flags().IntP("port", "p", 8080, "Port to run server on")
+	serverCmd.Flags().String("tls-cert", "", "TLS certificate file; serves HTTPS together with --tls-key")
+	serverCmd.Flags().String("tls-key", "", "TLS private key file")
 }
 
 var execCmd = &cobra.Command{
@@ -362,18 +530,34 @@ var execCmd = &cobra.Command{
 			return
 		}
 
+		revealed, err := svc.ComposeSnippet(selectedSnippet.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error getting snippet: %v", err)))
+			return
+		}
+		opts := snippetRenderOptions(cmd, svc, promptPlaceholders(bufio.NewReader(os.Stdin), revealed, snippet.PlaceholderNames(revealed.Command), nil))
+		command, err := revealed.Render(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error rendering snippet: %v", err)))
+			return
+		}
+
 		// Copy to clipboard and show info
 		fmt.Printf("\n%s\n", cli.InfoColor.Sprintf("📋 Selected: %s", selectedSnippet.Name))
 		if selectedSnippet.Description != "" {
 			fmt.Printf("%s\n", cli.ColorizeDescription(selectedSnippet.Description))
 		}
-		fmt.Printf("%s\n", cli.CommandColor.Sprintf("Command: %s", selectedSnippet.Command))
+		if revealed.Secret {
+			fmt.Printf("%s\n", cli.CommandColor.Sprintf("Command: %s", snippet.SecretMask))
+		} else {
+			fmt.Printf("%s\n", cli.CommandColor.Sprintf("Command: %s", command))
+		}
 
 		// Try to copy to clipboard
-		err = copyToClipboard(selectedSnippet.Command)
+		err = copyToClipboard(command)
 		if err != nil {
 			fmt.Printf("\n%s\n", cli.ColorizeWarning("Could not copy to clipboard. Here's the command:"))
-			fmt.Println(selectedSnippet.Command)
+			fmt.Println(command)
 		} else {
 			fmt.Printf("\n%s\n", cli.ColorizeSuccess("✅ Command copied to clipboard! Paste it in your terminal."))
 		}
@@ -390,6 +574,26 @@ var configureCmd = &cobra.Command{
 		fmt.Println()
 		fmt.Println("📁 SNI_CONFIG_DIR    - Custom config directory")
 		fmt.Printf("   Current: %s\n", getConfigInfo())
+		fmt.Println("🔑 SNI_PASSPHRASE    - Passphrase for secret snippets")
+		fmt.Println("🔑 SNI_KEYFILE       - Keyfile for secret snippets (default: <config dir>/secret.key)")
+		fmt.Println()
+		fmt.Println("Other settings are read from config.yaml in the config directory:")
+		fmt.Println()
+		fmt.Println("  resolve:                       # References allowed in snippets")
+		fmt.Println("    env: [\"AWS_*\"]               # {{env:AWS_PROFILE}}")
+		fmt.Println("    file: [\"~/.token\"]           # {{file:~/.token}}")
+		fmt.Println("    cmd: [\"pass show *\"]         # {{cmd:pass show db}}")
+		fmt.Println("  tags:")
+		fmt.Println("    synonyms:                    # Tags replaced by a canonical tag")
+		fmt.Println("      kubernetes: [k8s, kube]")
+		fmt.Println("  backup:                        # Backups taken before each save")
+		fmt.Println("    keep: 20                     # 0 disables backups")
+		fmt.Println("    max_age_days: 30")
+		fmt.Println("  storage:")
+		fmt.Println("    backend: yaml                # yaml (snippets.yaml), dir (one file per snippet) or sqlite (snippets.db)")
+		fmt.Println("  server:")
+		fmt.Println("    allowed_origins:             # browser origins allowed to call the API besides the server itself")
+		fmt.Println("      - https://snippets.example.com")
 		fmt.Println()
 		fmt.Println("Example usage:")
 		fmt.Println("  export SNI_CONFIG_DIR=\"/path/to/config\"")
@@ -422,35 +626,101 @@ func getConfigInfo() string {
 	return workDir + "/.sni (default)"
 }
 
+// parseSetValues parses repeated name=value flags into placeholder values
+func parseSetValues(pairs []string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid placeholder value '%s' (expected name=value)", pair)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// snippetRenderOptions builds render options, resolving references unless --no-resolve is set
+func snippetRenderOptions(cmd *cobra.Command, svc *snippet.Service, values map[string]string) snippet.RenderOptions {
+	opts := snippet.RenderOptions{Values: values}
+	if noResolve, _ := cmd.Flags().GetBool("no-resolve"); !noResolve {
+		opts.Resolver = svc.Resolver()
+	}
+	return opts
+}
+
+// promptPlaceholders asks for a value for each named placeholder that has none in values yet
+func promptPlaceholders(reader *bufio.Reader, s *snippet.Snippet, names []string, values map[string]string) map[string]string {
+	if values == nil {
+		values = make(map[string]string)
+	}
+	for _, name := range names {
+		if _, ok := values[name]; ok {
+			continue
+		}
+
+		defaultValue := ""
+		if p := s.Placeholder(name); p != nil {
+			defaultValue = p.Default
+			if p.Secret && defaultValue != "" {
+				defaultValue = snippet.SecretMask
+			}
+		}
+
+		fmt.Printf("%s [%s]: ", name, defaultValue)
+		value, _ := reader.ReadString('\n')
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values[name] = value
+		}
+	}
+	return values
+}
+
 func copyToClipboard(text string) error {
-	var cmd *exec.Cmd
+	cmd, err := clipboardCommand()
+	if err != nil {
+		return err
+	}
 
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// clipboardCommand returns the command that copies its stdin to the clipboard on this platform
+func clipboardCommand() (*exec.Cmd, error) {
 	switch runtime.GOOS {
 	case "darwin":
-		cmd = exec.Command("pbcopy")
+		return exec.Command("pbcopy"), nil
 	case "linux":
 		// Try xclip first, then xsel
 		if _, err := exec.LookPath("xclip"); err == nil {
-			cmd = exec.Command("xclip", "-selection", "clipboard")
+			return exec.Command("xclip", "-selection", "clipboard"), nil
 		} else if _, err := exec.LookPath("xsel"); err == nil {
-			cmd = exec.Command("xsel", "--clipboard", "--input")
-		} else {
-			return fmt.Errorf("no clipboard utility found (install xclip or xsel)")
+			return exec.Command("xsel", "--clipboard", "--input"), nil
 		}
+		return nil, fmt.Errorf("no clipboard utility found (install xclip or xsel)")
 	case "windows":
-		cmd = exec.Command("clip")
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+		return exec.Command("clip"), nil
 	}
-
-	cmd.Stdin = strings.NewReader(text)
-	return cmd.Run()
+	return nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
 }
 
 func init() {
 	execCmd.Flags().StringP("tag", "t", "", "Filter snippets by tag")
 	execCmd.Flags().Bool("color", false, "Enable colorized output")
+	execCmd.Flags().Bool("no-resolve", false, "Copy env/file/cmd references without resolving them")
+
+	newCmd.Flags().Bool("secret", false, "Store the snippet content encrypted")
+	newCmd.Flags().Bool("allow-any-name", false, "Skip the snippet naming rules")
+	newCmd.Flags().StringArray("alias", nil, "Add an alternative name (repeatable)")
+	newCmd.Flags().StringArray("attach", nil, "Bundle a local file with the snippet (repeatable)")
+	renameCmd.Flags().Bool("allow-any-name", false, "Skip the snippet naming rules")
+	rmCmd.Flags().Bool("force", false, "Delete even if other snippets include it")
+	useCmd.Flags().StringArray("set", nil, "Set a placeholder value (name=value)")
+	useCmd.Flags().Bool("no-resolve", false, "Show env/file/cmd references without resolving them")
+	useCmd.Flags().String("file", "", "Output a bundled file instead of the command")
 
 	listCmd.Flags().Bool("color", false, "Enable colorized output")
+	addFilterFlags(listCmd)
 	searchCmd.Flags().Bool("color", false, "Enable colorized output")
 }
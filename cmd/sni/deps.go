@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/atobaum/snippet-manager/internal/cli"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var depsCmd = &cobra.Command{
+	Use:   "deps <name>",
+	Short: "Show the snippets a snippet includes and the snippets that include it",
+	Long: `Show the {{> name}} include graph of a snippet: the snippets it includes,
+recursively, and the snippets that include it directly or indirectly.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSnippetNames(1, false),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		colorEnabled, _ := cmd.Flags().GetBool("color")
+		cli.EnableColors(colorEnabled)
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error initializing service: %v", err)))
+			return
+		}
+
+		// Resolve aliases to the snippet name used in the graph
+		target, err := svc.GetSnippet(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+		name = target.Name
+
+		graph, err := svc.IncludeGraph()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error loading snippets: %v", err)))
+			return
+		}
+
+		fmt.Println(cli.ColorizeTitle("Includes:"))
+		fmt.Println(cli.NameColor.Sprint(name))
+		printDeps(graph, name, "", []string{name}, func(n string) []string { return graph[n] })
+
+		fmt.Println()
+		fmt.Println(cli.ColorizeTitle("Included by:"))
+		fmt.Println(cli.NameColor.Sprint(name))
+		printDeps(graph, name, "", []string{name}, func(n string) []string { return snippet.Dependents(graph, n) })
+	},
+}
+
+// printDeps prints the edges returned by next as a tree, marking cycles and missing snippets
+func printDeps(graph map[string][]string, name, indent string, path []string, next func(string) []string) {
+	children := next(name)
+	for i, child := range children {
+		branch, nextIndent := "├── ", indent+"│   "
+		if i == len(children)-1 {
+			branch, nextIndent = "└── ", indent+"    "
+		}
+
+		line := indent + branch + cli.NameColor.Sprint(child)
+		_, exists := graph[child]
+		cycle := containsName(path, child)
+		switch {
+		case !exists:
+			line += " " + cli.ErrorColor.Sprint("(missing)")
+		case cycle:
+			line += " " + cli.WarningColor.Sprint("(cycle)")
+		}
+		fmt.Println(line)
+
+		if exists && !cycle {
+			printDeps(graph, child, nextIndent, append(path, child), next)
+		}
+	}
+}
+
+// containsName checks if a name is in the list
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	depsCmd.Flags().Bool("color", false, "Enable colorized output")
+}
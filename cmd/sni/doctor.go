@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/cli"
+	"github.com/atobaum/snippet-manager/internal/config"
+	"github.com/atobaum/snippet-manager/internal/selector"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the snippet library and environment for problems",
+	Long: `Report the resolved config directory, file permissions, problems in
+snippets.yaml and the availability of fzf and a clipboard tool.
+
+With --fix, safe repairs are applied: missing directories are created, loose
+permissions are tightened, name fields are set to their keys and missing
+timestamps are filled in. Exits with status 1 if errors remain.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		colorEnabled, _ := cmd.Flags().GetBool("color")
+		fix, _ := cmd.Flags().GetBool("fix")
+		cli.EnableColors(colorEnabled)
+
+		d := &doctor{fix: fix}
+		fmt.Println(cli.TitleColor.Sprint("🩺 sni doctor"))
+		fmt.Println()
+
+		cfg, err := config.DefaultConfig()
+		if err != nil {
+			d.fail("Config: %v", err)
+			d.summary()
+			return
+		}
+
+		fmt.Printf("📁 Config directory: %s\n", getConfigInfo())
+		if d.checkDir(cfg.ConfigDir) {
+			d.checkMode(storagePath(cfg), 0022, "writable by other users")
+			keyFile := os.Getenv("SNI_KEYFILE")
+			if keyFile == "" {
+				keyFile = cfg.KeyFile
+			}
+			d.checkMode(keyFile, 0077, "readable by other users")
+			d.checkMode(cfg.TokenFile, 0077, "readable by other users")
+			d.checkMode(cfg.ReadOnlyTokenFile, 0077, "readable by other users")
+			d.checkSnippets(storageLabel(cfg))
+		}
+
+		if selector.IsFzfAvailable() {
+			d.ok("fzf is installed")
+		} else {
+			d.info("fzf is not installed; exec falls back to numbered selection")
+		}
+		if clip, err := clipboardCommand(); err != nil {
+			d.warn(false, "Clipboard: %v", err)
+		} else if clip.Err != nil {
+			d.warn(false, "Clipboard: %v", clip.Err)
+		} else {
+			d.ok("Clipboard: %s", filepath.Base(clip.Path))
+		}
+
+		d.summary()
+	},
+}
+
+// doctor prints check results and counts the problems found
+type doctor struct {
+	fix      bool
+	errors   int
+	warnings int
+	fixable  int
+}
+
+func (d *doctor) ok(format string, args ...interface{}) {
+	fmt.Println(cli.ColorizeSuccess(fmt.Sprintf(format, args...)))
+}
+
+func (d *doctor) info(format string, args ...interface{}) {
+	fmt.Println(cli.ColorizeInfo(fmt.Sprintf(format, args...)))
+}
+
+func (d *doctor) warn(fixable bool, format string, args ...interface{}) {
+	d.warnings++
+	message := fmt.Sprintf(format, args...)
+	if fixable {
+		d.fixable++
+		message += " (fixable)"
+	}
+	fmt.Println(cli.ColorizeWarning(message))
+}
+
+func (d *doctor) fail(format string, args ...interface{}) {
+	d.errors++
+	fmt.Println(cli.ColorizeError(fmt.Sprintf(format, args...)))
+}
+
+func (d *doctor) fixed(format string, args ...interface{}) {
+	fmt.Println(cli.ColorizeSuccess("Fixed: " + fmt.Sprintf(format, args...)))
+}
+
+// checkDir checks that the config directory exists and is writable, and reports whether it exists
+func (d *doctor) checkDir(dir string) bool {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		if !d.fix {
+			d.warn(true, "%s does not exist yet", dir)
+			return false
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			d.fail("Creating %s: %v", dir, err)
+			return false
+		}
+		d.fixed("created %s", dir)
+		return true
+	}
+	if err != nil {
+		d.fail("%s: %v", dir, err)
+		return false
+	}
+	if !info.IsDir() {
+		d.fail("%s is not a directory", dir)
+		return false
+	}
+
+	probe, err := os.CreateTemp(dir, ".sni-doctor-*")
+	if err != nil {
+		d.fail("%s is not writable: %v", dir, err)
+		return true
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	d.ok("%s is writable", dir)
+	return true
+}
+
+// checkMode reports a file whose permissions include any of the loose bits
+func (d *doctor) checkMode(path string, loose os.FileMode, problem string) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		d.fail("%s: %v", path, err)
+		return
+	}
+
+	mode := info.Mode().Perm()
+	if mode&loose == 0 {
+		d.ok("%s (mode %04o)", filepath.Base(path), mode)
+		return
+	}
+	if !d.fix {
+		d.warn(true, "%s is %s (mode %04o)", filepath.Base(path), problem, mode)
+		return
+	}
+	if err := os.Chmod(path, mode&^loose); err != nil {
+		d.fail("Changing mode of %s: %v", path, err)
+		return
+	}
+	d.fixed("%s mode %04o -> %04o", filepath.Base(path), mode, mode&^loose)
+}
+
+// checkSnippets reports problems in the stored snippets and repairs the fixable ones with --fix
+func (d *doctor) checkSnippets(label string) {
+	svc, err := snippet.NewService()
+	if err != nil {
+		d.fail("Initializing service: %v", err)
+		return
+	}
+
+	problems, err := svc.CheckSnippets()
+	if err != nil {
+		d.fail("%v", err)
+		return
+	}
+	if len(problems) == 0 {
+		d.ok("%s has no problems", label)
+		return
+	}
+
+	fixable := 0
+	for _, p := range problems {
+		var location []string
+		if p.Line > 0 {
+			location = append(location, fmt.Sprintf("line %d", p.Line))
+		}
+		if p.Column > 0 {
+			location = append(location, fmt.Sprintf("column %d", p.Column))
+		}
+		prefix := label
+		if len(location) > 0 {
+			prefix += " " + strings.Join(location, ", ")
+		}
+		if p.Snippet != "" {
+			prefix += fmt.Sprintf(" '%s'", p.Snippet)
+		}
+
+		switch {
+		case p.Fixable && d.fix:
+			fixable++
+		case p.Fixable:
+			d.warn(true, "%s: %s", prefix, p.Message)
+		case p.Snippet != "":
+			d.warn(false, "%s: %s", prefix, p.Message)
+		default:
+			d.fail("%s: %s", prefix, p.Message)
+		}
+	}
+
+	if fixable > 0 {
+		repaired, err := svc.RepairSnippets()
+		if err != nil {
+			d.fail("Repairing snippets: %v", err)
+			return
+		}
+		d.fixed("repaired %d snippet(s)", repaired)
+	}
+}
+
+// storageLabel names where snippets are stored for messages
+func storageLabel(cfg *config.Config) string {
+	if cfg.Storage.Backend == snippet.StorageDir {
+		return filepath.Base(cfg.SnippetDir) + "/"
+	}
+	return filepath.Base(storagePath(cfg))
+}
+
+// storagePath returns the file or directory of the configured storage backend
+func storagePath(cfg *config.Config) string {
+	switch cfg.Storage.Backend {
+	case snippet.StorageDir:
+		return cfg.SnippetDir
+	case snippet.StorageSQLite:
+		return cfg.SnippetDB
+	}
+	return cfg.SnippetFile
+}
+
+// summary prints the totals and exits with status 1 if errors remain
+func (d *doctor) summary() {
+	fmt.Println()
+	if d.errors == 0 && d.warnings == 0 {
+		fmt.Println(cli.ColorizeSuccess("Everything looks good"))
+		return
+	}
+
+	summary := fmt.Sprintf("%d error(s), %d warning(s)", d.errors, d.warnings)
+	if d.fixable > 0 {
+		summary += fmt.Sprintf("; run 'sni doctor --fix' to repair %d of them", d.fixable)
+	}
+	if d.errors > 0 {
+		fmt.Println(cli.ColorizeError(summary))
+		os.Exit(1)
+	}
+	fmt.Println(cli.ColorizeWarning(summary))
+}
+
+func init() {
+	doctorCmd.Flags().Bool("fix", false, "Apply safe repairs")
+	doctorCmd.Flags().Bool("color", false, "Enable colorized output")
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/exporter"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export snippets to other formats",
+	Long: `Export snippets as a json or yaml bundle, a markdown cheatsheet,
+a VS Code .code-snippets file, or a shell script of aliases and functions.
+
+Secret snippets stay encrypted in json and yaml bundles and are masked
+or omitted in the other formats. The other formats also inline {{> name}}
+includes; a snippet that includes a secret snippet is treated as secret.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error initializing service: %v\n", err)
+			return
+		}
+
+		filter := snippetFilter(cmd)
+		namespace, _ := cmd.Flags().GetString("namespace")
+		filter.Namespace = strings.Trim(namespace, snippet.NamespaceSeparator)
+
+		snippets, err := svc.SealedSnippets(filter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading snippets: %v\n", err)
+			return
+		}
+		if !exporter.KeepsIncludes(format) {
+			if snippets, err = svc.InlineIncludes(snippets); err != nil {
+				fmt.Fprintf(os.Stderr, "Error expanding includes: %v\n", err)
+				return
+			}
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", output, err)
+				return
+			}
+			defer file.Close()
+			w = file
+		}
+
+		if err := exporter.Export(w, snippets, format); err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting snippets: %v\n", err)
+			return
+		}
+
+		if output != "" {
+			fmt.Printf("✅ Exported %d snippet(s) to %s\n", len(snippets), output)
+		}
+	},
+}
+
+// addFilterFlags adds the snippet selection flags shared by list and export
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceP("tag", "t", nil, "Only snippets with this tag (repeatable)")
+	cmd.Flags().StringP("lang", "l", "", "Only snippets in this language")
+	cmd.Flags().StringP("query", "q", "", "Only snippets matching this keyword")
+}
+
+// snippetFilter builds a filter from the selection flags
+func snippetFilter(cmd *cobra.Command) snippet.Filter {
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	language, _ := cmd.Flags().GetString("lang")
+	keyword, _ := cmd.Flags().GetString("query")
+	return snippet.Filter{
+		Tags:     tags,
+		Language: language,
+		Keyword:  keyword,
+	}
+}
+
+func init() {
+	exportCmd.Flags().StringP("format", "f", "yaml", "Export format ("+strings.Join(exporter.Formats, ", ")+")")
+	exportCmd.Flags().StringP("output", "o", "", "Write to a file instead of stdout")
+	exportCmd.Flags().StringP("namespace", "n", "", "Only snippets in this namespace, such as k8s/")
+	addFilterFlags(exportCmd)
+}
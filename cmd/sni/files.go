@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var materializeCmd = &cobra.Command{
+	Use:               "materialize <name> <dir>",
+	Short:             "Write the files bundled with a snippet into a directory",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeSnippetNames(1, false),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, dir := args[0], args[1]
+		force, _ := cmd.Flags().GetBool("force")
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error initializing service: %v\n", err)
+			return
+		}
+
+		s, err := svc.RevealSnippet(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting snippet: %v\n", err)
+			return
+		}
+		if len(s.Files) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: snippet '%s' has no files\n", name)
+			return
+		}
+
+		written, err := snippet.WriteFiles(dir, s.Files, force)
+		for _, path := range written {
+			fmt.Printf("  %s\n", path)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing files: %v\n", err)
+			return
+		}
+
+		fmt.Printf("✅ %d file(s) of snippet '%s' written to %s\n", len(written), name, dir)
+	},
+}
+
+// readBundleFiles reads local files to bundle with a snippet, named by their base name
+func readBundleFiles(paths []string) ([]snippet.File, error) {
+	var files []snippet.File
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		files = append(files, snippet.File{
+			Name:    filepath.Base(path),
+			Content: string(data),
+		})
+	}
+	return files, snippet.ValidateFiles(files)
+}
+
+func init() {
+	materializeCmd.Flags().Bool("force", false, "Overwrite existing files")
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/cli"
+	"github.com/atobaum/snippet-manager/internal/harvest"
+	"github.com/atobaum/snippet-manager/internal/selector"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var harvestCmd = &cobra.Command{
+	Use:   "harvest",
+	Short: "Create snippets from shell history",
+	Long: `Read bash, zsh (including extended format) or fish history, rank frequently
+used long commands, and pick the ones to save as snippets.
+
+Without --file, the history files of all common shells are read.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		files, _ := cmd.Flags().GetStringArray("file")
+		shell, _ := cmd.Flags().GetString("shell")
+		limit, _ := cmd.Flags().GetInt("limit")
+		minLength, _ := cmd.Flags().GetInt("min-length")
+		colorEnabled, _ := cmd.Flags().GetBool("color")
+		cli.EnableColors(colorEnabled)
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error initializing service: %v", err)))
+			return
+		}
+
+		if len(files) == 0 {
+			files = harvest.DefaultHistoryFiles()
+		}
+		if len(files) == 0 {
+			fmt.Println(cli.ColorizeWarning("No shell history files found. Use --file to point at one."))
+			return
+		}
+
+		var entries []harvest.Entry
+		for _, file := range files {
+			format := shell
+			if format == "" {
+				format = harvest.DetectShell(file)
+			}
+			fileEntries, err := harvest.ReadFile(file, format)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error reading %s: %v", file, err)))
+				return
+			}
+			entries = append(entries, fileEntries...)
+		}
+
+		// Skip commands that are already saved
+		existing, err := svc.ListSnippets()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error loading snippets: %v", err)))
+			return
+		}
+		var saved []string
+		for _, s := range existing {
+			saved = append(saved, s.Command)
+		}
+
+		candidates := harvest.Rank(entries, harvest.Options{
+			MinLength: minLength,
+			Limit:     limit,
+			Exclude:   saved,
+		})
+		if len(candidates) == 0 {
+			fmt.Println(cli.ColorizeWarning("No new commands worth saving were found."))
+			return
+		}
+
+		suggestions := make([]snippet.Snippet, len(candidates))
+		descriptions := make(map[string]string, len(candidates))
+		for i, c := range candidates {
+			suggestions[i] = c.Snippet()
+			descriptions[c.Command] = suggestions[i].Description
+			// Show the command in the selector; the saved snippet keeps its own description
+			suggestions[i].Description = fmt.Sprintf("%s (used %d times)", commandPreview(c.Command), c.Count)
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		sel := selector.NewSelector(colorEnabled)
+		created := 0
+		for len(suggestions) > 0 {
+			prompt := cli.ColorizeTitle(fmt.Sprintf("Select a command to save (%d candidates, cancel when done):", len(suggestions)))
+			picked, err := sel.Select(suggestions, prompt)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Selection error: %v", err)))
+				break
+			}
+			if picked == nil {
+				break
+			}
+			chosen := *picked
+			chosen.Description = descriptions[chosen.Command]
+
+			// Offer a free name, since earlier picks may have taken the suggested one
+			defaultName, err := svc.UniqueName(chosen.Name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error loading snippets: %v", err)))
+				break
+			}
+
+			fmt.Printf("\n%s\n", cli.CommandColor.Sprintf("Command: %s", chosen.Command))
+			fmt.Printf("Name [%s]: ", defaultName)
+			name, _ := reader.ReadString('\n')
+			chosen.Name = defaultName
+			if name = strings.TrimSpace(name); name != "" {
+				chosen.Name = name
+			}
+
+			// A failed add keeps the candidate so it can be picked again with another name
+			if err := svc.AddSnippet(chosen); err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error creating snippet: %v", err)))
+				continue
+			}
+			suggestions = removeSuggestion(suggestions, chosen.Command)
+			created++
+			fmt.Println(cli.ColorizeSuccess(fmt.Sprintf("Snippet '%s' created with tags: %s", chosen.Name, strings.Join(chosen.Tags, ", "))))
+		}
+
+		fmt.Println(cli.ColorizeInfo(fmt.Sprintf("%d snippet(s) created from history", created)))
+	},
+}
+
+// commandPreview shortens a command to one line for the selector
+func commandPreview(command string) string {
+	preview := []rune(strings.Join(strings.Fields(command), " "))
+	if len(preview) > 80 {
+		return string(preview[:80]) + "..."
+	}
+	return string(preview)
+}
+
+// removeSuggestion drops the suggestion for a command from the list
+func removeSuggestion(suggestions []snippet.Snippet, command string) []snippet.Snippet {
+	remaining := suggestions[:0]
+	for _, s := range suggestions {
+		if s.Command != command {
+			remaining = append(remaining, s)
+		}
+	}
+	return remaining
+}
+
+func init() {
+	harvestCmd.Flags().StringArray("file", nil, "History file to read (repeatable)")
+	harvestCmd.Flags().String("shell", "", "History format of --file (bash, zsh, fish); detected from the file name by default")
+	harvestCmd.Flags().Int("limit", 50, "Maximum number of candidates to offer")
+	harvestCmd.Flags().Int("min-length", 20, "Ignore commands shorter than this many characters")
+	harvestCmd.Flags().Bool("color", false, "Enable colorized output")
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/cli"
+	"github.com/atobaum/snippet-manager/internal/importer"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import --from <format> <path>",
+	Short: "Import snippets from other snippet tools",
+	Long: `Import snippets from other snippet tools.
+
+Supported formats:
+  pet      pet snippet.toml
+  navi     navi .cheat file or directory
+  cheat    cheat cheatsheet file or directory
+  vscode   VS Code .code-snippets or language snippet JSON files
+  espanso  espanso match file or directory`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+		format, _ := cmd.Flags().GetString("from")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		conflict, _ := cmd.Flags().GetString("on-conflict")
+		colorEnabled, _ := cmd.Flags().GetBool("color")
+		cli.EnableColors(colorEnabled)
+
+		policy, err := snippet.ParseImportPolicy(conflict)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(err.Error()))
+			return
+		}
+
+		imp, err := importer.New(format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(err.Error()))
+			return
+		}
+
+		snippets, err := imp.Import(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error reading %s: %v", path, err)))
+			return
+		}
+
+		if len(snippets) == 0 {
+			fmt.Println(cli.ColorizeWarning(fmt.Sprintf("No snippets found in %s", path)))
+			return
+		}
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error initializing service: %v", err)))
+			return
+		}
+
+		results, err := svc.ImportSnippets(snippets, policy, dryRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error importing snippets: %v", err)))
+			return
+		}
+
+		printImportReport(results, dryRun)
+	},
+}
+
+// printImportReport prints each imported snippet and a summary by action
+func printImportReport(results []snippet.ImportResult, dryRun bool) {
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[r.Action]++
+	}
+	skipped := counts[snippet.ImportSkipped]
+
+	title := fmt.Sprintf("Imported %d snippet(s)", len(results)-skipped)
+	if dryRun {
+		title = fmt.Sprintf("Dry run: %d snippet(s) would be imported", len(results)-skipped)
+	}
+	if skipped > 0 {
+		title += fmt.Sprintf(", %d skipped", skipped)
+	}
+	fmt.Printf("%s\n\n", cli.ColorizeTitle(title+":"))
+
+	for _, r := range results {
+		switch {
+		case r.Duplicate:
+			fmt.Printf("  %-12s %s → %s (duplicate name in import)\n", r.Action, r.OriginalName, cli.NameColor.Sprint(r.Name))
+		case r.Action == snippet.ImportRenamed:
+			fmt.Printf("  %-12s %s → %s\n", r.Action, r.OriginalName, cli.NameColor.Sprint(r.Name))
+		case r.Action == snippet.ImportSkipped:
+			fmt.Printf("  %-12s %s\n", r.Action, cli.CommandColor.Sprint(r.Name))
+		default:
+			fmt.Printf("  %-12s %s\n", r.Action, cli.NameColor.Sprint(r.Name))
+		}
+	}
+
+	var summary []string
+	for _, action := range []string{snippet.ImportCreated, snippet.ImportOverwritten, snippet.ImportRenamed, snippet.ImportSkipped} {
+		if counts[action] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[action], action))
+		}
+	}
+	fmt.Printf("\n%s\n", cli.ColorizeInfo(strings.Join(summary, ", ")))
+}
+
+func init() {
+	importCmd.Flags().String("from", "", "Source format ("+strings.Join(importer.Formats, ", ")+")")
+	importCmd.Flags().Bool("dry-run", false, "Show what would be imported without saving")
+	importCmd.Flags().String("on-conflict", "skip", "What to do with existing names (skip, overwrite, rename)")
+	importCmd.Flags().Bool("color", false, "Enable colorized output")
+	importCmd.MarkFlagRequired("from")
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/atobaum/snippet-manager/internal/cli"
+	"github.com/atobaum/snippet-manager/internal/lint"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [name...]",
+	Short: "Check snippets for syntax errors and common mistakes",
+	Long: `Check snippet commands and files according to their language:
+YAML and JSON must parse, bash and sh must parse and should quote expansions,
+and {{...}} directives must name placeholders, references or existing snippets.
+
+Without names every snippet is checked. Exits with status 1 if errors were found.`,
+	ValidArgsFunction: completeSnippetNames(-1, false),
+	Run: func(cmd *cobra.Command, args []string) {
+		colorEnabled, _ := cmd.Flags().GetBool("color")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		cli.EnableColors(colorEnabled)
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error initializing service: %v", err)))
+			return
+		}
+
+		results, err := lint.Snippets(svc, args...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error linting snippets: %v", err)))
+			return
+		}
+
+		errorCount, warningCount := 0, 0
+		for _, result := range results {
+			errorCount += result.Count(lint.SeverityError)
+			warningCount += result.Count(lint.SeverityWarning)
+
+			var shown []lint.Diagnostic
+			for _, d := range result.Diagnostics {
+				if !quiet || d.Severity != lint.SeverityInfo {
+					shown = append(shown, d)
+				}
+			}
+			if len(shown) == 0 {
+				continue
+			}
+
+			fmt.Println(cli.ColorizeSnippetName(result.Name))
+			for _, d := range shown {
+				location := d.Location()
+				if location != "" {
+					location += " "
+				}
+				fmt.Printf("   %s%s %s %s\n", cli.CommandColor.Sprint(location), colorizeSeverity(d.Severity), d.Message, cli.CommandColor.Sprintf("(%s)", d.Rule))
+			}
+			fmt.Println()
+		}
+
+		if errorCount == 0 && warningCount == 0 {
+			fmt.Println(cli.ColorizeSuccess(fmt.Sprintf("No problems found in %d snippet(s)", len(results))))
+			return
+		}
+
+		summary := fmt.Sprintf("Found %d error(s) and %d warning(s) in %d snippet(s)", errorCount, warningCount, len(results))
+		if errorCount > 0 {
+			fmt.Println(cli.ColorizeError(summary))
+			os.Exit(1)
+		}
+		fmt.Println(cli.ColorizeWarning(summary))
+	},
+}
+
+// colorizeSeverity formats a diagnostic severity in its color
+func colorizeSeverity(severity lint.Severity) string {
+	switch severity {
+	case lint.SeverityError:
+		return cli.ErrorColor.Sprint(severity)
+	case lint.SeverityWarning:
+		return cli.WarningColor.Sprint(severity)
+	}
+	return cli.InfoColor.Sprint(severity)
+}
+
+func init() {
+	lintCmd.Flags().Bool("color", false, "Enable colorized output")
+	lintCmd.Flags().BoolP("quiet", "q", false, "Hide informational diagnostics")
+}
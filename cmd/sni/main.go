@@ -25,11 +25,30 @@ func init() {
 	// Add subcommands
 	rootCmd.AddCommand(newCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(treeCmd)
 	rootCmd.AddCommand(searchCmd)
 	rootCmd.AddCommand(useCmd)
+	rootCmd.AddCommand(materializeCmd)
+	rootCmd.AddCommand(scaffoldCmd)
 	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(renameCmd)
+	rootCmd.AddCommand(aliasCmd)
+	rootCmd.AddCommand(pinCmd)
+	rootCmd.AddCommand(unpinCmd)
+	rootCmd.AddCommand(pinnedCmd)
+	rootCmd.AddCommand(tagsCmd)
 	rootCmd.AddCommand(rmCmd)
+	rootCmd.AddCommand(depsCmd)
+	rootCmd.AddCommand(lintCmd)
 	rootCmd.AddCommand(execCmd)
 	rootCmd.AddCommand(configureCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(migrateStorageCmd)
 	rootCmd.AddCommand(serverCmd)
+	rootCmd.AddCommand(mergeDriverCmd)
+	rootCmd.AddCommand(secretCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(harvestCmd)
 }
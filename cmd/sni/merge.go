@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var mergeDriverCmd = &cobra.Command{
+	Use:   "merge-driver <base> <current> <other>",
+	Short: "Merge snippets.yaml files (git merge driver)",
+	Long: `Merge two versions of snippets.yaml against their common ancestor at the
+snippet and field level. The result is written to <current>.
+
+Register it as a git merge driver:
+  git config merge.sni.name "sni snippets merge"
+  git config merge.sni.driver "sni merge-driver %O %A %B"
+  echo "snippets.yaml merge=sni" >> .gitattributes`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		strict, _ := cmd.Flags().GetBool("strict")
+
+		var files [3]*snippet.SnippetsFile
+		for i, path := range args {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+				os.Exit(2)
+			}
+			files[i], err = snippet.ParseSnippetsFile(data)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+				os.Exit(2)
+			}
+		}
+
+		merged, conflicts := snippet.MergeSnippetsFiles(files[0], files[1], files[2])
+
+		data, err := yaml.Marshal(merged)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling merged snippets: %v\n", err)
+			os.Exit(2)
+		}
+		if err := os.WriteFile(args[1], data, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing merged snippets: %v\n", err)
+			os.Exit(2)
+		}
+
+		if len(conflicts) == 0 {
+			return
+		}
+
+		fmt.Fprintf(os.Stderr, "⚠️  %d conflicting change(s) resolved by newest update:\n", len(conflicts))
+		for _, c := range conflicts {
+			fmt.Fprintf(os.Stderr, "  %s\n", c)
+		}
+		if strict {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	mergeDriverCmd.Flags().Bool("strict", false, "Exit with failure when conflicting changes were found")
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/cli"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var treeCmd = &cobra.Command{
+	Use:               "tree [namespace/]",
+	Short:             "Show snippets as a tree of namespaces",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeSnippetNames(1, true),
+	Run: func(cmd *cobra.Command, args []string) {
+		colorEnabled, _ := cmd.Flags().GetBool("color")
+		cli.EnableColors(colorEnabled)
+
+		namespace := ""
+		if len(args) > 0 {
+			namespace = strings.Trim(args[0], snippet.NamespaceSeparator)
+		}
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error initializing service: %v", err)))
+			return
+		}
+
+		snippets, err := svc.FilterSnippets(snippet.Filter{Namespace: namespace})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error listing snippets: %v", err)))
+			return
+		}
+
+		if len(snippets) == 0 {
+			fmt.Println(cli.ColorizeWarning("No snippets found."))
+			return
+		}
+
+		root := newTreeNode()
+		for _, s := range snippets {
+			relative := strings.TrimPrefix(s.Name, namespace+snippet.NamespaceSeparator)
+			if namespace == "" {
+				relative = s.Name
+			}
+			root.add(strings.Split(relative, snippet.NamespaceSeparator), s.Description)
+		}
+
+		if namespace == "" {
+			fmt.Println(cli.HeaderColor.Sprint("."))
+		} else {
+			fmt.Println(cli.HeaderColor.Sprint(namespace + snippet.NamespaceSeparator))
+		}
+		root.print("")
+	},
+}
+
+// treeNode is a namespace with its snippets and child namespaces
+type treeNode struct {
+	snippets   map[string]string
+	namespaces map[string]*treeNode
+}
+
+func newTreeNode() *treeNode {
+	return &treeNode{
+		snippets:   make(map[string]string),
+		namespaces: make(map[string]*treeNode),
+	}
+}
+
+// add inserts a snippet given its name segments relative to this node
+func (n *treeNode) add(segments []string, description string) {
+	if len(segments) == 1 {
+		n.snippets[segments[0]] = description
+		return
+	}
+	child, ok := n.namespaces[segments[0]]
+	if !ok {
+		child = newTreeNode()
+		n.namespaces[segments[0]] = child
+	}
+	child.add(segments[1:], description)
+}
+
+// print writes the node's children with box-drawing branches
+func (n *treeNode) print(indent string) {
+	type entry struct {
+		name  string
+		child *treeNode
+	}
+
+	var entries []entry
+	for name := range n.namespaces {
+		entries = append(entries, entry{name: name, child: n.namespaces[name]})
+	}
+	for name := range n.snippets {
+		entries = append(entries, entry{name: name})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].name != entries[j].name {
+			return entries[i].name < entries[j].name
+		}
+		return entries[i].child != nil
+	})
+
+	for i, e := range entries {
+		branch, nextIndent := "├── ", indent+"│   "
+		if i == len(entries)-1 {
+			branch, nextIndent = "└── ", indent+"    "
+		}
+
+		if e.child != nil {
+			fmt.Println(indent + branch + cli.HeaderColor.Sprint(e.name+snippet.NamespaceSeparator))
+			e.child.print(nextIndent)
+			continue
+		}
+
+		line := indent + branch + cli.NameColor.Sprint(e.name)
+		if description := n.snippets[e.name]; description != "" {
+			line += cli.CommandColor.Sprintf(" - %s", description)
+		}
+		fmt.Println(line)
+	}
+}
+
+// completeSnippetNames completes snippet names and aliases for the first maxArgs arguments
+// (-1 means all arguments), one namespace level at a time. With namespacesOnly, only namespaces of snippet names are offered.
+func completeSnippetNames(maxArgs int, namespacesOnly bool) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if maxArgs >= 0 && len(args) >= maxArgs {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		snippets, err := svc.ListSnippets()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		directive := cobra.ShellCompDirectiveNoFileComp
+		seen := make(map[string]bool)
+		var completions []string
+		var names []string
+		for _, s := range snippets {
+			names = append(names, s.Name)
+			if !namespacesOnly {
+				names = append(names, s.Aliases...)
+			}
+		}
+
+		for _, name := range names {
+			if !strings.HasPrefix(name, toComplete) {
+				continue
+			}
+
+			completion := name
+			rest := strings.TrimPrefix(name, toComplete)
+			if i := strings.Index(rest, snippet.NamespaceSeparator); i >= 0 {
+				// Stop at the next namespace so the user can keep typing
+				completion = toComplete + rest[:i+1]
+				directive |= cobra.ShellCompDirectiveNoSpace
+			} else if namespacesOnly {
+				continue
+			}
+
+			if !seen[completion] {
+				seen[completion] = true
+				completions = append(completions, completion)
+			}
+		}
+
+		return completions, directive
+	}
+}
+
+func init() {
+	treeCmd.Flags().Bool("color", false, "Enable colorized output")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/atobaum/snippet-manager/internal/cli"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var pinCmd = &cobra.Command{
+	Use:               "pin <name>",
+	Short:             "Pin a snippet to the top of lists",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSnippetNames(1, false),
+	Run: func(cmd *cobra.Command, args []string) {
+		setPinned(args[0], true)
+	},
+}
+
+var unpinCmd = &cobra.Command{
+	Use:               "unpin <name>",
+	Short:             "Unpin a snippet",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSnippetNames(1, false),
+	Run: func(cmd *cobra.Command, args []string) {
+		setPinned(args[0], false)
+	},
+}
+
+var pinnedCmd = &cobra.Command{
+	Use:   "pinned",
+	Short: "List pinned snippets",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		colorEnabled, _ := cmd.Flags().GetBool("color")
+		cli.EnableColors(colorEnabled)
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error initializing service: %v", err)))
+			return
+		}
+
+		snippets, err := svc.FilterSnippets(snippet.Filter{Pinned: true})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error listing snippets: %v", err)))
+			return
+		}
+
+		if len(snippets) == 0 {
+			fmt.Println(cli.ColorizeWarning("No pinned snippets. Pin one with 'sni pin <name>'"))
+			return
+		}
+
+		for _, s := range snippets {
+			line := cli.ColorizePinnedName(s.Name)
+			if s.Description != "" {
+				line += cli.CommandColor.Sprintf(" - %s", s.Description)
+			}
+			fmt.Println(line)
+		}
+	},
+}
+
+func setPinned(name string, pinned bool) {
+	svc, err := snippet.NewService()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing service: %v\n", err)
+		return
+	}
+
+	if err := svc.SetPinned(name, pinned); err != nil {
+		fmt.Fprintf(os.Stderr, "Error updating snippet: %v\n", err)
+		return
+	}
+
+	if pinned {
+		fmt.Printf("✅ Snippet '%s' pinned!\n", name)
+	} else {
+		fmt.Printf("✅ Snippet '%s' unpinned!\n", name)
+	}
+}
+
+func init() {
+	pinnedCmd.Flags().Bool("color", false, "Enable colorized output")
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/cli"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var scaffoldCmd = &cobra.Command{
+	Use:   "scaffold <name> <target-dir>",
+	Short: "Create a project from a multi-file snippet",
+	Long: `Render the files bundled with a snippet into a target directory.
+Placeholders are filled in both file contents and file paths. The snippet's
+hooks are shown and run in the target directory afterwards, each only after
+confirmation unless --run-hooks is given. Placeholder values are shell-quoted
+inside hooks.
+
+Placeholders not given with --set are asked for interactively.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeSnippetNames(1, false),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, dir := args[0], args[1]
+		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		noHooks, _ := cmd.Flags().GetBool("no-hooks")
+		runHooks, _ := cmd.Flags().GetBool("run-hooks")
+		colorEnabled, _ := cmd.Flags().GetBool("color")
+		cli.EnableColors(colorEnabled)
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error initializing service: %v", err)))
+			return
+		}
+
+		setValues, _ := cmd.Flags().GetStringArray("set")
+		values, err := parseSetValues(setValues)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+
+		s, err := svc.RevealSnippet(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error getting snippet: %v", err)))
+			return
+		}
+		if len(s.Files) == 0 {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error: snippet '%s' has no files", name)))
+			return
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		values = promptPlaceholders(reader, s, s.FilePlaceholderNames(), values)
+		opts := snippetRenderOptions(cmd, svc, values)
+
+		files, err := s.RenderFiles(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error rendering files: %v", err)))
+			return
+		}
+
+		var hooks []string
+		if !noHooks {
+			hookOpts := opts
+			hookOpts.Quote = snippet.ShellQuote
+			for _, hook := range s.Hooks {
+				rendered, err := s.RenderText(hook, hookOpts)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error rendering hook: %v", err)))
+					return
+				}
+				hooks = append(hooks, rendered)
+			}
+		}
+
+		if dryRun {
+			printScaffoldPreview(dir, files, hooks, force)
+			return
+		}
+
+		written, err := snippet.WriteFiles(dir, files, force)
+		for _, path := range written {
+			fmt.Printf("  %s\n", cli.NameColor.Sprint(path))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error writing files: %v", err)))
+			return
+		}
+
+		for _, hook := range hooks {
+			fmt.Println(cli.CommandColor.Sprintf("$ %s", hook))
+			if !runHooks && !confirmHook(reader) {
+				fmt.Println(cli.ColorizeWarning("Hook skipped"))
+				continue
+			}
+			if err := runHook(dir, hook); err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Hook failed: %v", err)))
+				return
+			}
+		}
+
+		fmt.Println(cli.ColorizeSuccess(fmt.Sprintf("Scaffolded '%s' into %s (%d file(s))", name, dir, len(written))))
+	},
+}
+
+// printScaffoldPreview shows the files that would be written as a tree, followed by the hooks
+func printScaffoldPreview(dir string, files []snippet.File, hooks []string, force bool) {
+	root := newTreeNode()
+	conflicts := 0
+	for _, f := range files {
+		status := ""
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(f.Name))); err == nil {
+			conflicts++
+			status = "exists"
+			if force {
+				status = "overwrite"
+			}
+		}
+		root.add(strings.Split(f.Name, "/"), status)
+	}
+
+	fmt.Println(cli.HeaderColor.Sprint(dir))
+	root.print("")
+
+	if len(hooks) > 0 {
+		fmt.Printf("\n%s\n", cli.ColorizeTitle("Hooks:"))
+		for _, hook := range hooks {
+			fmt.Println(cli.CommandColor.Sprintf("$ %s", hook))
+		}
+	}
+
+	if conflicts > 0 && !force {
+		fmt.Printf("\n%s\n", cli.ColorizeWarning(fmt.Sprintf("%d file(s) already exist; use --force to overwrite", conflicts)))
+	}
+}
+
+// confirmHook asks whether to run the hook just shown
+func confirmHook(reader *bufio.Reader) bool {
+	fmt.Print("Run this hook? (y/N): ")
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Println()
+	}
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// runHook runs a post-render hook with the shell in the target directory
+func runHook(dir, hook string) error {
+	c := exec.Command("sh", "-c", hook)
+	c.Dir = dir
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+func init() {
+	scaffoldCmd.Flags().Bool("force", false, "Overwrite existing files")
+	scaffoldCmd.Flags().Bool("dry-run", false, "Show the files and hooks without writing or running anything")
+	scaffoldCmd.Flags().Bool("no-hooks", false, "Do not run the snippet's hooks")
+	scaffoldCmd.Flags().Bool("run-hooks", false, "Run the snippet's hooks without asking for confirmation")
+	scaffoldCmd.Flags().StringArray("set", nil, "Set a placeholder value (name=value)")
+	scaffoldCmd.Flags().Bool("no-resolve", false, "Leave env/file/cmd references unresolved")
+	scaffoldCmd.Flags().Bool("color", false, "Enable colorized output")
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/config"
+	"github.com/atobaum/snippet-manager/internal/secret"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage encrypted snippet content",
+	Long: `Secret snippets and placeholders are stored encrypted in snippets.yaml.
+The key is derived from SNI_PASSPHRASE, or from the keyfile named by SNI_KEYFILE
+(default: <config dir>/secret.key).`,
+}
+
+var secretKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate a keyfile for encrypting secrets",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.DefaultConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			return
+		}
+
+		if err := os.MkdirAll(cfg.ConfigDir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating config directory: %v\n", err)
+			return
+		}
+
+		if err := secret.GenerateKeyFile(cfg.KeyFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating keyfile: %v\n", err)
+			return
+		}
+
+		fmt.Printf("✅ Keyfile written to %s\n", cfg.KeyFile)
+		fmt.Println("Keep it out of version control; secrets cannot be decrypted without it.")
+	},
+}
+
+var secretOnCmd = &cobra.Command{
+	Use:               "on <name>",
+	Short:             "Mark a snippet as secret and encrypt its content",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSnippetNames(1, false),
+	Run: func(cmd *cobra.Command, args []string) {
+		setSecret(args[0], true)
+	},
+}
+
+var secretOffCmd = &cobra.Command{
+	Use:               "off <name>",
+	Short:             "Store a secret snippet's content in plain text again",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSnippetNames(1, false),
+	Run: func(cmd *cobra.Command, args []string) {
+		setSecret(args[0], false)
+	},
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:               "set <name> <placeholder>",
+	Short:             "Store an encrypted default value for a placeholder",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeSnippetNames(1, false),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, placeholder := args[0], args[1]
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error initializing service: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Value for %s: ", placeholder)
+		value, err := readSecretValue()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading value: %v\n", err)
+			return
+		}
+		if value == "" {
+			fmt.Println("No value given.")
+			return
+		}
+
+		if err := svc.SetSecretPlaceholder(name, placeholder, value); err != nil {
+			fmt.Fprintf(os.Stderr, "Error updating snippet: %v\n", err)
+			return
+		}
+
+		fmt.Printf("✅ Secret value for '%s' stored in snippet '%s'!\n", placeholder, name)
+	},
+}
+
+// readSecretValue reads one line from stdin, without echoing it when stdin is a terminal
+func readSecretValue() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		value, err := term.ReadPassword(fd)
+		fmt.Println()
+		return string(value), err
+	}
+
+	value, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(value, "\r\n"), nil
+}
+
+func setSecret(name string, enabled bool) {
+	svc, err := snippet.NewService()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing service: %v\n", err)
+		return
+	}
+
+	if err := svc.SetSecret(name, enabled); err != nil {
+		fmt.Fprintf(os.Stderr, "Error updating snippet: %v\n", err)
+		return
+	}
+
+	if enabled {
+		fmt.Printf("✅ Snippet '%s' is now stored encrypted!\n", name)
+	} else {
+		fmt.Printf("✅ Snippet '%s' is now stored in plain text!\n", name)
+	}
+}
+
+func init() {
+	secretCmd.AddCommand(secretKeygenCmd)
+	secretCmd.AddCommand(secretOnCmd)
+	secretCmd.AddCommand(secretOffCmd)
+	secretCmd.AddCommand(secretSetCmd)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/config"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var migrateStorageCmd = &cobra.Command{
+	Use:   "migrate-storage --to yaml|dir|sqlite",
+	Short: "Convert snippets between storage layouts",
+	Long: `Copy every snippet to another storage layout and switch to it:
+
+  yaml  a single snippets.yaml file
+  dir   one file per snippet under snippets/, with YAML front matter followed
+        by the command; namespaces become directories and the extension
+        follows the language (k8s/pod/logs.sh)
+  sqlite  a snippets.db database with a full-text index; search, filters and
+          tag counts run as SQL queries instead of loading every snippet
+
+The copy is read back and compared before storage.backend is updated in
+config.yaml. The previous layout is left in place.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		to, _ := cmd.Flags().GetString("to")
+		force, _ := cmd.Flags().GetBool("force")
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error initializing service: %v\n", err)
+			return
+		}
+
+		migrated, err := svc.MigrateStorage(to, force)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error migrating storage: %v\n", err)
+			return
+		}
+
+		cfg, err := config.DefaultConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			return
+		}
+
+		fmt.Printf("✅ Migrated %d snippet(s) to %s storage (%s)\n", migrated, to, storageLabel(cfg))
+		fmt.Println("   storage.backend was updated in config.yaml; the previous layout was left in place.")
+	},
+}
+
+func init() {
+	migrateStorageCmd.Flags().String("to", "", "Target storage layout ("+strings.Join(snippet.StorageBackends, "|")+")")
+	migrateStorageCmd.Flags().Bool("force", false, "Replace snippets already stored in the target layout")
+	migrateStorageCmd.MarkFlagRequired("to")
+	migrateStorageCmd.RegisterFlagCompletionFunc("to", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return snippet.StorageBackends, cobra.ShellCompDirectiveNoFileComp
+	})
+}
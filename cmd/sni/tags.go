@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/cli"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "List tags with their snippet counts, or manage them",
+	Long: `List every tag with the number of snippets using it.
+
+Tags are trimmed, lowercased and mapped through the synonyms in config.yaml
+whenever a snippet is created or edited:
+
+  tags:
+    synonyms:
+      kubernetes: [k8s, kube]`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		colorEnabled, _ := cmd.Flags().GetBool("color")
+		cli.EnableColors(colorEnabled)
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error initializing service: %v", err)))
+			return
+		}
+
+		tags, err := svc.TagCounts()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", cli.ColorizeError(fmt.Sprintf("Error loading tags: %v", err)))
+			return
+		}
+
+		if len(tags) == 0 {
+			fmt.Println(cli.ColorizeWarning("No tags found."))
+			return
+		}
+
+		for _, tc := range tags {
+			fmt.Printf("%s %s\n", cli.NumberColor.Sprintf("%4d", tc.Count), cli.TagColor.Sprintf("#%s", tc.Tag))
+		}
+	},
+}
+
+var tagsRenameCmd = &cobra.Command{
+	Use:               "rename <old> <new>",
+	Short:             "Rename a tag on all snippets",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeTags(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		changeTags(func(svc *snippet.Service) (string, int, error) {
+			changed, err := svc.RenameTag(args[0], args[1])
+			return fmt.Sprintf("renamed '%s' to '%s'", args[0], svc.NormalizeTag(args[1])), changed, err
+		})
+	},
+}
+
+var tagsMergeCmd = &cobra.Command{
+	Use:               "merge <tag>... <into>",
+	Short:             "Replace several tags with one tag on all snippets",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeTags(-1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sources, target := args[:len(args)-1], args[len(args)-1]
+		changeTags(func(svc *snippet.Service) (string, int, error) {
+			changed, err := svc.MergeTags(sources, target)
+			return fmt.Sprintf("merged %s into '%s'", strings.Join(sources, ", "), svc.NormalizeTag(target)), changed, err
+		})
+	},
+}
+
+var tagsRmCmd = &cobra.Command{
+	Use:               "rm <tag>",
+	Short:             "Remove a tag from all snippets",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTags(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		changeTags(func(svc *snippet.Service) (string, int, error) {
+			changed, err := svc.RemoveTag(args[0])
+			return fmt.Sprintf("removed '%s'", args[0]), changed, err
+		})
+	},
+}
+
+// changeTags applies a tag change and reports its summary and how many snippets it touched
+func changeTags(change func(*snippet.Service) (string, int, error)) {
+	svc, err := snippet.NewService()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing service: %v\n", err)
+		return
+	}
+
+	summary, changed, err := change(svc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error updating tags: %v\n", err)
+		return
+	}
+
+	fmt.Printf("✅ Tag %s (%d snippet(s) updated)\n", summary, changed)
+}
+
+// completeTags completes tag names for the first maxArgs arguments; -1 means all arguments
+func completeTags(maxArgs int) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if maxArgs >= 0 && len(args) >= maxArgs {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		svc, err := snippet.NewService()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		tags, err := svc.TagCounts()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var completions []string
+		for _, tc := range tags {
+			if strings.HasPrefix(tc.Tag, toComplete) {
+				completions = append(completions, tc.Tag)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func init() {
+	tagsCmd.Flags().Bool("color", false, "Enable colorized output")
+
+	tagsCmd.AddCommand(tagsRenameCmd)
+	tagsCmd.AddCommand(tagsMergeCmd)
+	tagsCmd.AddCommand(tagsRmCmd)
+}
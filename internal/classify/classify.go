@@ -0,0 +1,230 @@
+package classify
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Result is what the classifier inferred from snippet content
+type Result struct {
+	// Language is empty when no language could be inferred
+	Language string
+	Tags     []string
+}
+
+// programTags maps programs to the tags they suggest
+var programTags = map[string][]string{
+	"kubectl": {"k8s"}, "helm": {"k8s", "helm"}, "kustomize": {"k8s"}, "k9s": {"k8s"}, "minikube": {"k8s"}, "kind": {"k8s"},
+	"docker": {"docker"}, "docker-compose": {"docker"}, "podman": {"docker"},
+	"git": {"git"}, "gh": {"git", "github"},
+	"aws": {"aws"}, "gcloud": {"gcp"}, "gsutil": {"gcp"}, "az": {"azure"},
+	"terraform": {"terraform"}, "ansible": {"ansible"}, "ansible-playbook": {"ansible"},
+	"npm": {"node"}, "npx": {"node"}, "yarn": {"node"}, "pnpm": {"node"}, "node": {"node"},
+	"pip": {"python"}, "pip3": {"python"}, "python": {"python"}, "python3": {"python"}, "poetry": {"python"},
+	"go": {"go"}, "cargo": {"rust"},
+	"psql": {"sql", "postgres"}, "pg_dump": {"sql", "postgres"}, "mysql": {"sql", "mysql"}, "sqlite3": {"sql", "sqlite"},
+	"redis-cli": {"redis"},
+	"ssh":       {"ssh"}, "scp": {"ssh"}, "rsync": {"ssh"},
+	"curl": {"http"}, "wget": {"http"},
+	"systemctl": {"systemd"}, "journalctl": {"systemd"},
+	"jq": {"json"}, "yq": {"yaml"},
+	"ffmpeg":  {"media"},
+	"openssl": {"crypto"}, "gpg": {"crypto"},
+	"tar": {"archive"}, "zip": {"archive"}, "unzip": {"archive"},
+}
+
+var (
+	shebangPattern    = regexp.MustCompile(`^#!\s*(?:/usr/bin/env\s+(?:-S\s+)?)?(?:\S*/)?([A-Za-z]+)`)
+	dockerfilePattern = regexp.MustCompile(`(?im)^(FROM|RUN|COPY|ADD|CMD|ENTRYPOINT|ENV|WORKDIR|EXPOSE|ARG|USER|LABEL)\s`)
+	goPattern         = regexp.MustCompile(`(?m)^package \w+\s*$|^func (\(\w+ \*?\w+\) )?\w+\(|^import \(`)
+	pythonPattern     = regexp.MustCompile(`(?m)^\s*(def \w+\(.*\):|class \w+(\(.*\))?:|from [\w.]+ import |import \w+\s*$|if __name__ == ["']__main__["']:)`)
+	sqlPattern        = regexp.MustCompile(`(?is)^\s*(SELECT\s.+\sFROM\s|INSERT\s+INTO\s|UPDATE\s+\S+\s+SET\s|DELETE\s+FROM\s|CREATE\s+(TABLE|INDEX|VIEW|DATABASE)\s|ALTER\s+TABLE\s|DROP\s+(TABLE|INDEX|VIEW|DATABASE)\s|WITH\s+\w+\s+AS\s*\()`)
+	shellPattern      = regexp.MustCompile(`(?m)(^\s*(for|while|if)\s.*;\s*(do|then)\b|\$\{?\w+\}?|\$\(|\|\s*\w|&&|^\s*export\s+\w+=)`)
+	commandPattern    = regexp.MustCompile(`^[A-Za-z0-9_./~-]+$`)
+)
+
+// shebangLanguages maps shebang interpreters to languages
+var shebangLanguages = map[string]string{
+	"bash": "bash", "sh": "bash", "zsh": "zsh", "fish": "fish",
+	"python": "python", "python3": "python",
+	"node": "javascript", "deno": "typescript", "ruby": "ruby", "perl": "perl",
+}
+
+// extensionLanguages maps file extensions to languages
+var extensionLanguages = map[string]string{
+	".sh": "bash", ".bash": "bash", ".zsh": "zsh", ".fish": "fish",
+	".py": "python", ".go": "go", ".sql": "sql", ".json": "json",
+	".yaml": "yaml", ".yml": "yaml", ".toml": "toml", ".md": "markdown",
+	".js": "javascript", ".ts": "typescript", ".rb": "ruby", ".rs": "rust",
+}
+
+// languageExtensions maps languages to the extension their files are saved with
+var languageExtensions = map[string]string{
+	"bash": ".sh", "sh": ".sh", "shell": ".sh", "zsh": ".zsh", "fish": ".fish",
+	"python": ".py", "go": ".go", "sql": ".sql", "json": ".json",
+	"yaml": ".yaml", "toml": ".toml", "markdown": ".md", "dockerfile": ".dockerfile",
+	"javascript": ".js", "typescript": ".ts", "ruby": ".rb", "rust": ".rs", "perl": ".pl",
+}
+
+// FileExtension returns the file extension for a language, or ".txt" for unknown languages
+func FileExtension(language string) string {
+	if ext, ok := languageExtensions[strings.ToLower(language)]; ok {
+		return ext
+	}
+	return ".txt"
+}
+
+// Classify infers the language of content and suggests tags for it
+func Classify(content string) Result {
+	language, tags := detect(content)
+	for _, tag := range SuggestTags(content) {
+		if !containsString(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return Result{Language: language, Tags: tags}
+}
+
+// DetectLanguage infers the language of content, or returns "" if unsure
+func DetectLanguage(content string) string {
+	language, _ := detect(content)
+	return language
+}
+
+// DetectFileLanguage infers the language of a file from its name, falling back to its content
+func DetectFileLanguage(name, content string) string {
+	base := path.Base(name)
+	if base == "Dockerfile" || strings.HasSuffix(base, ".Dockerfile") || strings.HasPrefix(base, "Dockerfile.") {
+		return "dockerfile"
+	}
+	if language, ok := extensionLanguages[strings.ToLower(path.Ext(base))]; ok {
+		return language
+	}
+	return DetectLanguage(content)
+}
+
+// detect returns the language of content and tags implied by its structure
+func detect(content string) (string, []string) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", nil
+	}
+
+	if match := shebangPattern.FindStringSubmatch(content); match != nil {
+		interpreter := strings.TrimRight(match[1], "0123456789.")
+		if language, ok := shebangLanguages[interpreter]; ok {
+			return language, nil
+		}
+		if language, ok := shebangLanguages[match[1]]; ok {
+			return language, nil
+		}
+	}
+
+	if strings.HasPrefix(strings.ToUpper(content), "FROM ") || len(dockerfilePattern.FindAllString(content, -1)) >= 3 {
+		if dockerfilePattern.MatchString(firstLine(content)) {
+			return "dockerfile", []string{"docker"}
+		}
+	}
+
+	if sqlPattern.MatchString(content) {
+		return "sql", []string{"sql"}
+	}
+
+	if (strings.HasPrefix(content, "{") || strings.HasPrefix(content, "[")) && json.Valid([]byte(content)) {
+		return "json", nil
+	}
+
+	if goPattern.MatchString(content) {
+		return "go", nil
+	}
+
+	if pythonPattern.MatchString(content) {
+		return "python", nil
+	}
+
+	if isYAML(content) {
+		if isKubernetesManifest(content) {
+			return "yaml", []string{"k8s"}
+		}
+		return "yaml", nil
+	}
+
+	if shellPattern.MatchString(content) || len(Programs(content)) > 0 && looksLikeCommand(content) {
+		return "bash", nil
+	}
+
+	return "", nil
+}
+
+// SuggestTags returns tags for the programs a command runs, such as k8s for kubectl
+func SuggestTags(content string) []string {
+	var tags []string
+	for _, line := range strings.Split(content, "\n") {
+		for _, program := range Programs(line) {
+			for _, tag := range programTags[program] {
+				if !containsString(tags, tag) {
+					tags = append(tags, tag)
+				}
+			}
+		}
+	}
+	return tags
+}
+
+// isYAML checks if content is a YAML mapping or list spanning several lines
+func isYAML(content string) bool {
+	if !strings.Contains(content, "\n") || !strings.Contains(content, ":") {
+		return false
+	}
+	var doc interface{}
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	if err := decoder.Decode(&doc); err != nil {
+		return false
+	}
+	switch doc.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+// isKubernetesManifest checks if YAML content declares apiVersion and kind at the top level
+func isKubernetesManifest(content string) bool {
+	hasAPIVersion, hasKind := false, false
+	for _, line := range strings.Split(content, "\n") {
+		hasAPIVersion = hasAPIVersion || strings.HasPrefix(line, "apiVersion:")
+		hasKind = hasKind || strings.HasPrefix(line, "kind:")
+	}
+	return hasAPIVersion && hasKind
+}
+
+// looksLikeCommand checks if the first line of content reads like a command line:
+// a program name followed by flags or paths, or a program with known tags
+func looksLikeCommand(content string) bool {
+	fields := strings.Fields(firstLine(content))
+	for len(fields) > 0 && (IsWrapper(fields[0]) || strings.Contains(fields[0], "=")) {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 || !commandPattern.MatchString(fields[0]) {
+		return false
+	}
+	if _, known := programTags[fields[0]]; known {
+		return true
+	}
+	for _, field := range fields[1:] {
+		if strings.HasPrefix(field, "-") || strings.Contains(field, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// firstLine returns the first line of text
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
@@ -0,0 +1,74 @@
+package classify
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// wrapperPrograms run another command given as their arguments
+var wrapperPrograms = map[string]bool{
+	"sudo": true, "env": true, "time": true, "nohup": true, "exec": true, "command": true,
+}
+
+// shellKeywords start compound commands; the ones mapped to true prefix a regular command
+var shellKeywords = map[string]bool{
+	"do": true, "then": true, "else": true, "!": true,
+	"for": false, "while": false, "until": false, "if": false, "elif": false, "case": false,
+	"done": false, "fi": false, "esac": false, "in": false,
+}
+
+// IsWrapper checks if a program runs the command given as its arguments, like sudo
+func IsWrapper(program string) bool {
+	return wrapperPrograms[program]
+}
+
+// Programs returns the distinct programs invoked by a command line, in order
+func Programs(command string) []string {
+	var programs []string
+	for _, segment := range splitPipeline(command) {
+		if program := segmentProgram(segment); program != "" && !containsString(programs, program) {
+			programs = append(programs, program)
+		}
+	}
+	return programs
+}
+
+// splitPipeline splits a command line on |, &&, || and ;
+func splitPipeline(command string) []string {
+	return strings.FieldsFunc(command, func(r rune) bool {
+		return r == '|' || r == '&' || r == ';' || r == '\n'
+	})
+}
+
+// segmentProgram returns the program of a simple command, skipping assignments, wrappers and keywords
+func segmentProgram(segment string) string {
+	for _, word := range strings.Fields(segment) {
+		if prefix, isKeyword := shellKeywords[word]; isKeyword {
+			if prefix {
+				continue
+			}
+			return ""
+		}
+
+		switch {
+		case strings.Contains(word, "=") && !strings.HasPrefix(word, "-"):
+			continue
+		case wrapperPrograms[word], strings.HasPrefix(word, "-"):
+			continue
+		case strings.ContainsAny(word, "(){}$\"'`#"):
+			return ""
+		}
+		return filepath.Base(word)
+	}
+	return ""
+}
+
+// containsString checks if a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -34,6 +34,11 @@ func ColorizeSnippetName(name string) string {
 	return NameColor.Sprintf("🔹 %s", name)
 }
 
+// ColorizePinnedName formats a pinned snippet name with color
+func ColorizePinnedName(name string) string {
+	return NameColor.Sprintf("⭐ %s", name)
+}
+
 // ColorizeDescription formats description with color
 func ColorizeDescription(desc string) string {
 	if desc == "" {
@@ -50,6 +55,14 @@ func ColorizeLanguage(language string) string {
 	return "   " + InfoColor.Sprintf("Language: %s", language)
 }
 
+// ColorizeAliases formats aliases with color
+func ColorizeAliases(aliases []string) string {
+	if len(aliases) == 0 {
+		return ""
+	}
+	return "   " + InfoColor.Sprintf("Aliases: %s", strings.Join(aliases, ", "))
+}
+
 // ColorizeTags formats tags with color
 func ColorizeTags(tags []string) string {
 	if len(tags) == 0 {
@@ -1,15 +1,76 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Config holds the application configuration
 type Config struct {
-	ConfigDir   string
-	SnippetFile string
-	ServerPort  int
+	ConfigDir   string `yaml:"-"`
+	ConfigFile  string `yaml:"-"`
+	SnippetFile string `yaml:"-"`
+	SnippetDir  string `yaml:"-"`
+	SnippetDB   string `yaml:"-"`
+	KeyFile     string `yaml:"-"`
+	// TokenFile and ReadOnlyTokenFile hold the web server's API tokens
+	TokenFile         string `yaml:"-"`
+	ReadOnlyTokenFile string `yaml:"-"`
+	BackupDir         string `yaml:"-"`
+	ServerPort        int    `yaml:"-"`
+
+	// Settings read from config.yaml
+	Resolve ResolveConfig `yaml:"resolve"`
+	Naming  NamingConfig  `yaml:"naming"`
+	Tags    TagsConfig    `yaml:"tags"`
+	Backup  BackupConfig  `yaml:"backup"`
+	Storage StorageConfig `yaml:"storage"`
+	Server  ServerConfig  `yaml:"server"`
+}
+
+// ServerConfig controls access to the web server
+type ServerConfig struct {
+	// AllowedOrigins lists the origins, such as https://example.com, allowed to call the API
+	// from a browser in addition to the server's own origin
+	AllowedOrigins []string `yaml:"allowed_origins"`
+}
+
+// StorageConfig selects how snippets are stored
+type StorageConfig struct {
+	// Backend is "yaml" for a single snippets.yaml file, "dir" for one file per snippet
+	// or "sqlite" for a snippets.db database
+	Backend string `yaml:"backend"`
+}
+
+// BackupConfig controls the backups taken before snippets.yaml is overwritten
+type BackupConfig struct {
+	// Keep is the number of backups kept; 0 disables backups
+	Keep int `yaml:"keep"`
+	// MaxAgeDays removes backups older than this many days; 0 keeps them regardless of age
+	MaxAgeDays int `yaml:"max_age_days"`
+}
+
+// TagsConfig controls how snippet tags are normalized
+type TagsConfig struct {
+	// Synonyms maps a canonical tag to the tags that are replaced by it
+	Synonyms map[string][]string `yaml:"synonyms"`
+}
+
+// NamingConfig controls the snippet naming rules
+type NamingConfig struct {
+	// AllowAny disables the naming rules for new and renamed snippets
+	AllowAny bool `yaml:"allow_any"`
+}
+
+// ResolveConfig lists the references that may be resolved when rendering snippets.
+// Entries are patterns where * matches any sequence of characters.
+type ResolveConfig struct {
+	Env  []string `yaml:"env"`
+	File []string `yaml:"file"`
+	Cmd  []string `yaml:"cmd"`
 }
 
 // DefaultConfig returns the default configuration
@@ -33,9 +94,94 @@ func DefaultConfig() (*Config, error) {
 
 	snippetFile := filepath.Join(configDir, "snippets.yaml")
 
-	return &Config{
-		ConfigDir:   configDir,
-		SnippetFile: snippetFile,
-		ServerPort:  8080,
-	}, nil
+	cfg := &Config{
+		ConfigDir:         configDir,
+		ConfigFile:        filepath.Join(configDir, "config.yaml"),
+		SnippetFile:       snippetFile,
+		KeyFile:           filepath.Join(configDir, "secret.key"),
+		TokenFile:         filepath.Join(configDir, "server.token"),
+		ReadOnlyTokenFile: filepath.Join(configDir, "server-readonly.token"),
+		SnippetDir:        filepath.Join(configDir, "snippets"),
+		SnippetDB:         filepath.Join(configDir, "snippets.db"),
+		BackupDir:         filepath.Join(configDir, "backups"),
+		ServerPort:        8080,
+		Backup: BackupConfig{
+			Keep:       20,
+			MaxAgeDays: 30,
+		},
+		Storage: StorageConfig{
+			Backend: "yaml",
+		},
+	}
+
+	if err := cfg.loadFile(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadFile reads settings from config.yaml if it exists
+func (c *Config) loadFile() error {
+	data, err := os.ReadFile(c.ConfigFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", c.ConfigFile, err)
+	}
+
+	return nil
+}
+
+// SetStorageBackend records the storage backend in config.yaml, keeping its other settings and comments
+func (c *Config) SetStorageBackend(backend string) error {
+	var doc yaml.Node
+	data, err := os.ReadFile(c.ConfigFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", c.ConfigFile, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("config file %s is not a mapping", c.ConfigFile)
+	}
+
+	storage := mappingValue(doc.Content[0], "storage", yaml.MappingNode)
+	value := mappingValue(storage, "backend", yaml.ScalarNode)
+	value.Value, value.Tag, value.Style = backend, "!!str", 0
+
+	data, err = yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := os.WriteFile(c.ConfigFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	c.Storage.Backend = backend
+	return nil
+}
+
+// mappingValue returns the value node for key in a mapping node, adding a node of the given kind if missing
+func mappingValue(mapping *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			if mapping.Content[i+1].Kind != kind {
+				mapping.Content[i+1] = &yaml.Node{Kind: kind}
+			}
+			return mapping.Content[i+1]
+		}
+	}
+	value := &yaml.Node{Kind: kind}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
 }
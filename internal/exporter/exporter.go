@@ -0,0 +1,85 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats lists the supported export formats
+var Formats = []string{"json", "yaml", "markdown", "vscode", "shell"}
+
+// Export writes snippets in the given format. Snippets are expected in their stored form:
+// json and yaml bundles keep secret values encrypted, other formats mask or omit them.
+func Export(w io.Writer, snippets []snippet.Snippet, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(bundle(snippets))
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(bundle(snippets)); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case "markdown":
+		return exportMarkdown(w, snippets)
+	case "vscode":
+		return exportVSCode(w, snippets)
+	case "shell":
+		return exportShell(w, snippets)
+	}
+	return fmt.Errorf("unsupported format '%s' (supported: %s)", format, strings.Join(Formats, ", "))
+}
+
+// KeepsIncludes reports whether a format can be imported again and so keeps {{> name}} includes.
+// Snippets exported in other formats should have their includes inlined first.
+func KeepsIncludes(format string) bool {
+	return format == "json" || format == "yaml"
+}
+
+// ContentType returns the MIME type of an export format
+func ContentType(format string) string {
+	switch format {
+	case "json", "vscode":
+		return "application/json"
+	case "yaml":
+		return "application/yaml"
+	case "markdown":
+		return "text/markdown; charset=utf-8"
+	case "shell":
+		return "text/x-shellscript; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// FileName returns a default file name for an export format
+func FileName(format string) string {
+	switch format {
+	case "markdown":
+		return "snippets.md"
+	case "vscode":
+		return "sni.code-snippets"
+	case "shell":
+		return "snippets.sh"
+	}
+	return "snippets." + format
+}
+
+// bundle wraps snippets in the snippets.yaml file structure so exports can be imported and merged
+func bundle(snippets []snippet.Snippet) *snippet.SnippetsFile {
+	file := &snippet.SnippetsFile{
+		Version:  snippet.CurrentVersion,
+		Snippets: make(map[string]snippet.Snippet, len(snippets)),
+	}
+	for _, s := range snippets {
+		file.Snippets[s.Name] = s
+	}
+	return file
+}
@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/snippet"
+)
+
+// exportMarkdown writes a cheatsheet with one section per snippet
+func exportMarkdown(w io.Writer, snippets []snippet.Snippet) error {
+	var b strings.Builder
+
+	b.WriteString("# Snippets\n\n")
+	for _, s := range snippets {
+		fmt.Fprintf(&b, "- [%s](#%s)\n", s.Name, markdownAnchor(s.Name))
+	}
+
+	for _, s := range snippets {
+		s = s.Masked()
+
+		fmt.Fprintf(&b, "\n## %s\n\n", s.Name)
+		if s.Description != "" {
+			fmt.Fprintf(&b, "%s\n\n", s.Description)
+		}
+		if len(s.Tags) > 0 {
+			fmt.Fprintf(&b, "Tags: `%s`\n\n", strings.Join(s.Tags, "` `"))
+		}
+
+		writeCodeBlock(&b, s.Language, s.Command)
+		for _, f := range s.Files {
+			fmt.Fprintf(&b, "\n`%s`\n\n", f.Name)
+			writeCodeBlock(&b, f.Language, f.Content)
+		}
+
+		if len(s.Placeholders) > 0 {
+			b.WriteString("\n| Placeholder | Default | Description |\n|---|---|---|\n")
+			for _, p := range s.Placeholders {
+				fmt.Fprintf(&b, "| `%s` | %s | %s |\n", p.Name, markdownCell(p.Default), markdownCell(p.Description))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeCodeBlock writes a fenced code block, lengthening the fence if the code contains one
+func writeCodeBlock(b *strings.Builder, language, code string) {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(b, "%s%s\n%s\n%s\n", fence, language, strings.TrimRight(code, "\n"), fence)
+}
+
+// markdownAnchor returns the heading anchor generated by common markdown renderers
+func markdownAnchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ' || r == '-':
+			b.WriteRune('-')
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// markdownCell escapes a value for use in a table cell
+func markdownCell(value string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(value)
+}
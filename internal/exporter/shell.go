@@ -0,0 +1,153 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/snippet"
+)
+
+// nonIdentifierChars matches characters not allowed in shell function names
+var nonIdentifierChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// shellLanguages are the languages exported as shell aliases and functions
+var shellLanguages = map[string]bool{"": true, "bash": true, "sh": true, "shell": true, "zsh": true}
+
+// exportShell writes a script defining an alias or function per shell snippet.
+// Secret and non-shell snippets are skipped.
+func exportShell(w io.Writer, snippets []snippet.Snippet) error {
+	var b strings.Builder
+	b.WriteString("# Generated by sni export. Source this file from your shell rc.\n")
+
+	for _, s := range snippets {
+		name := shellIdentifier(s.Name)
+		switch {
+		case s.Secret:
+			fmt.Fprintf(&b, "\n# %s: skipped (secret)\n", s.Name)
+			continue
+		case !shellLanguages[strings.ToLower(s.Language)]:
+			fmt.Fprintf(&b, "\n# %s: skipped (%s)\n", s.Name, s.Language)
+			continue
+		}
+
+		b.WriteString("\n")
+		if s.Description != "" {
+			fmt.Fprintf(&b, "# %s\n", strings.ReplaceAll(s.Description, "\n", " "))
+		}
+
+		body, params, unresolved := shellBody(s.Masked())
+		for _, ref := range unresolved {
+			fmt.Fprintf(&b, "# warning: {{cmd:%s}} is left unresolved; run this snippet with sni to apply the resolve.cmd allowlist\n", strings.ReplaceAll(ref, "\n", " "))
+		}
+		if len(params) == 0 && !strings.Contains(strings.TrimSpace(body), "\n") {
+			fmt.Fprintf(&b, "alias %s=%s\n", name, shellQuote(strings.TrimSpace(body)))
+			continue
+		}
+
+		if invokesCommand(body, name) {
+			// A function must not shadow the command it runs
+			name = "sni_" + name
+		}
+
+		fmt.Fprintf(&b, "%s() {\n", name)
+		for i, p := range params {
+			fmt.Fprintf(&b, "  local %s=%s\n", p.variable, shellParam(i+1, p))
+		}
+		for _, line := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+		b.WriteString("}\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// shellPlaceholder maps a snippet placeholder onto a positional function argument
+type shellPlaceholder struct {
+	name         string
+	variable     string
+	defaultValue string
+}
+
+// shellBody converts placeholders to variables and env and file references to their shell
+// equivalents. Command references are left as written and returned, since the exported script
+// would run them unconditionally instead of checking the resolve.cmd allowlist.
+func shellBody(s snippet.Snippet) (string, []shellPlaceholder, []string) {
+	var params []shellPlaceholder
+	var unresolved []string
+	index := make(map[string]int)
+
+	body := snippet.ReplaceDirectives(s.Command, func(directive string) string {
+		if kind, ref, ok := snippet.ParseReference(directive); ok {
+			switch kind {
+			case snippet.RefEnv:
+				return "${" + ref + "}"
+			case snippet.RefFile:
+				return "$(cat " + shellPath(ref) + ")"
+			case snippet.RefCmd:
+				unresolved = append(unresolved, ref)
+				return "{{" + directive + "}}"
+			}
+		}
+		if !snippet.IsPlaceholderName(directive) {
+			return "{{" + directive + "}}"
+		}
+
+		i, ok := index[directive]
+		if !ok {
+			p := shellPlaceholder{name: directive, variable: "sni_" + shellIdentifier(directive)}
+			if ph := s.Placeholder(directive); ph != nil && ph.Default != snippet.SecretMask {
+				p.defaultValue = ph.Default
+			}
+			i = len(params)
+			index[directive] = i
+			params = append(params, p)
+		}
+		return "${" + params[i].variable + "}"
+	})
+
+	return body, params, unresolved
+}
+
+// shellPath quotes a file path, keeping a leading ~/ expandable
+func shellPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return `"$HOME"/` + shellQuote(rest)
+	}
+	return shellQuote(path)
+}
+
+// shellParam returns the expression reading a positional argument with its default
+func shellParam(position int, p shellPlaceholder) string {
+	if p.defaultValue == "" {
+		return fmt.Sprintf(`"${%d:?missing %s}"`, position, p.name)
+	}
+	return fmt.Sprintf(`"${%d:-%s}"`, position, strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`", "}", `\}`).Replace(p.defaultValue))
+}
+
+// shellIdentifier turns a snippet name into a valid shell function name
+func shellIdentifier(name string) string {
+	identifier := strings.Trim(nonIdentifierChars.ReplaceAllString(name, "_"), "_")
+	if identifier == "" || (identifier[0] >= '0' && identifier[0] <= '9') {
+		identifier = "sni_" + identifier
+	}
+	return identifier
+}
+
+// invokesCommand checks if any line of the body starts with the command name
+func invokesCommand(body, name string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote quotes a value for single-quoted shell strings
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
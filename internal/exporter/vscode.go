@@ -0,0 +1,64 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/snippet"
+)
+
+type vscodeSnippet struct {
+	Prefix      string   `json:"prefix"`
+	Body        []string `json:"body"`
+	Description string   `json:"description,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
+}
+
+// exportVSCode writes a .code-snippets file; secret snippets are omitted
+func exportVSCode(w io.Writer, snippets []snippet.Snippet) error {
+	entries := make(map[string]vscodeSnippet)
+	for _, s := range snippets {
+		if s.Secret {
+			continue
+		}
+		entries[s.Name] = vscodeSnippet{
+			Prefix:      s.Name,
+			Body:        strings.Split(vscodeBody(s.Masked()), "\n"),
+			Description: s.Description,
+			Scope:       s.Language,
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(entries)
+}
+
+// vscodeBody converts {{name}} placeholders into numbered tabstops
+func vscodeBody(s snippet.Snippet) string {
+	// $ starts tabstops and variables in VS Code snippets
+	body := strings.ReplaceAll(s.Command, "$", `\$`)
+
+	tabstops := make(map[string]int)
+	return snippet.ReplaceDirectives(body, func(directive string) string {
+		if !snippet.IsPlaceholderName(directive) {
+			return "{{" + directive + "}}"
+		}
+
+		n, ok := tabstops[directive]
+		if !ok {
+			n = len(tabstops) + 1
+			tabstops[directive] = n
+		}
+
+		label := directive
+		if p := s.Placeholder(directive); p != nil && p.Default != "" && p.Default != snippet.SecretMask {
+			label = p.Default
+		}
+		label = strings.NewReplacer("$", `\$`, "}", `\}`, ",", `\,`, "|", `\|`).Replace(label)
+		return fmt.Sprintf("${%d:%s}", n, label)
+	})
+}
@@ -0,0 +1,118 @@
+package harvest
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/atobaum/snippet-manager/internal/classify"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+)
+
+// Candidate is a distinct history command worth saving as a snippet
+type Candidate struct {
+	Command  string
+	Count    int
+	LastUsed time.Time
+	Score    float64
+}
+
+// Options controls which commands become candidates
+type Options struct {
+	// MinLength skips commands shorter than this many characters
+	MinLength int
+	// Limit caps the number of candidates; zero means no limit
+	Limit int
+	// Exclude lists commands to skip, such as those already saved as snippets
+	Exclude []string
+}
+
+// trivialPrograms are commands not worth saving on their own
+var trivialPrograms = map[string]bool{
+	"cd": true, "ls": true, "ll": true, "la": true, "pwd": true, "clear": true,
+	"exit": true, "history": true, "man": true, "which": true, "sni": true,
+}
+
+// Rank deduplicates history entries and orders them by frequency weighted by length
+func Rank(entries []Entry, opts Options) []Candidate {
+	excluded := make(map[string]bool)
+	for _, command := range opts.Exclude {
+		excluded[strings.TrimSpace(command)] = true
+	}
+
+	byCommand := make(map[string]*Candidate)
+	var candidates []*Candidate
+	for _, entry := range entries {
+		command := strings.TrimSpace(entry.Command)
+		if len(command) < opts.MinLength || excluded[command] || isTrivial(command) {
+			continue
+		}
+
+		c, ok := byCommand[command]
+		if !ok {
+			c = &Candidate{Command: command}
+			byCommand[command] = c
+			candidates = append(candidates, c)
+		}
+		c.Count++
+		if entry.Time.After(c.LastUsed) {
+			c.LastUsed = entry.Time
+		}
+	}
+
+	ranked := make([]Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		c.Score = float64(c.Count) * math.Log2(1+float64(len(c.Command)))
+		ranked = append(ranked, *c)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].LastUsed.After(ranked[j].LastUsed)
+	})
+
+	if opts.Limit > 0 && len(ranked) > opts.Limit {
+		ranked = ranked[:opts.Limit]
+	}
+	return ranked
+}
+
+// Snippet turns a candidate into a snippet with a suggested name and tags
+func (c Candidate) Snippet() snippet.Snippet {
+	var words []string
+	for _, word := range strings.Fields(c.Command) {
+		if strings.HasPrefix(word, "-") || strings.ContainsAny(word, "|;&<>$\"'") {
+			break
+		}
+		if len(words) == 0 && classify.IsWrapper(word) {
+			continue
+		}
+		words = append(words, filepath.Base(word))
+		if len(words) == 3 {
+			break
+		}
+	}
+
+	description := fmt.Sprintf("Harvested from shell history (used %d times)", c.Count)
+	language := classify.DetectLanguage(c.Command)
+	if language == "" {
+		language = "bash"
+	}
+	tags := append(classify.SuggestTags(c.Command), classify.Programs(c.Command)...)
+	return snippet.NewSnippet(snippet.Slugify(strings.Join(words, " ")), description, c.Command, language, tags)
+}
+
+// isTrivial checks if a command only runs trivial programs
+func isTrivial(command string) bool {
+	for _, program := range classify.Programs(command) {
+		if !trivialPrograms[program] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,233 @@
+package harvest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Shell history formats
+const (
+	Bash = "bash"
+	Zsh  = "zsh"
+	Fish = "fish"
+)
+
+// Entry is one command read from a history file
+type Entry struct {
+	Command string
+	Time    time.Time
+}
+
+var (
+	// zshExtendedPattern matches the ": <start>:<elapsed>;" prefix of zsh extended history
+	zshExtendedPattern = regexp.MustCompile(`^: *([0-9]+):[0-9]+;`)
+
+	// bashTimestampPattern matches "#<epoch>" lines written when HISTTIMEFORMAT is set
+	bashTimestampPattern = regexp.MustCompile(`^#([0-9]{9,})$`)
+)
+
+// DefaultHistoryFiles returns the existing history files of common shells
+func DefaultHistoryFiles() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	candidates := []string{
+		os.Getenv("HISTFILE"),
+		filepath.Join(home, ".bash_history"),
+		filepath.Join(home, ".zsh_history"),
+		filepath.Join(home, ".zhistory"),
+		filepath.Join(home, ".local", "share", "fish", "fish_history"),
+	}
+
+	var files []string
+	for _, path := range candidates {
+		if path == "" || containsString(files, path) {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// DetectShell guesses the history format from the file name
+func DetectShell(path string) string {
+	base := strings.ToLower(filepath.Base(path))
+	switch {
+	case strings.Contains(base, "fish"):
+		return Fish
+	case strings.Contains(base, "zsh"), strings.Contains(base, "zhistory"):
+		return Zsh
+	}
+	return Bash
+}
+
+// ReadFile parses a history file in the given format
+func ReadFile(path, shell string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch shell {
+	case Bash:
+		return ParseBash(file)
+	case Zsh:
+		return ParseZsh(file)
+	case Fish:
+		return ParseFish(file)
+	}
+	return nil, fmt.Errorf("unsupported shell '%s' (supported: bash, zsh, fish)", shell)
+}
+
+// ParseBash parses bash history, including HISTTIMEFORMAT timestamp lines
+func ParseBash(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	var timestamp time.Time
+
+	scanner := newScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := bashTimestampPattern.FindStringSubmatch(line); match != nil {
+			timestamp = parseEpoch(match[1])
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			entries = append(entries, Entry{Command: line, Time: timestamp})
+		}
+		timestamp = time.Time{}
+	}
+	return entries, scanner.Err()
+}
+
+// ParseZsh parses plain or extended zsh history with backslash-continued multi-line commands
+func ParseZsh(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	var current *Entry
+
+	for _, line := range strings.Split(string(unmetafy(data)), "\n") {
+		if current != nil {
+			// Continuation of a multi-line command
+			if strings.HasSuffix(current.Command, "\\") {
+				current.Command = strings.TrimSuffix(current.Command, "\\") + "\n" + line
+				continue
+			}
+			entries = append(entries, *current)
+			current = nil
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		entry := Entry{Command: line}
+		if match := zshExtendedPattern.FindStringSubmatch(line); match != nil {
+			entry.Time = parseEpoch(match[1])
+			entry.Command = line[len(match[0]):]
+		}
+		current = &entry
+	}
+	if current != nil {
+		entries = append(entries, *current)
+	}
+
+	return entries, nil
+}
+
+// ParseFish parses fish's YAML-like history file
+func ParseFish(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+
+	scanner := newScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "- cmd: "):
+			entries = append(entries, Entry{Command: unescapeFish(strings.TrimPrefix(line, "- cmd: "))})
+		case strings.HasPrefix(line, "  when: ") && len(entries) > 0:
+			entries[len(entries)-1].Time = parseEpoch(strings.TrimPrefix(line, "  when: "))
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// newScanner returns a line scanner that tolerates very long history lines
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return scanner
+}
+
+// unmetafy decodes zsh's metafied bytes: 0x83 followed by a byte XOR 32
+func unmetafy(data []byte) []byte {
+	if !bytes.Contains(data, []byte{0x83}) {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == 0x83 && i+1 < len(data) {
+			i++
+			out = append(out, data[i]^32)
+			continue
+		}
+		out = append(out, data[i])
+	}
+	return out
+}
+
+// unescapeFish decodes the \n and \\ escapes fish uses in history
+func unescapeFish(command string) string {
+	var b strings.Builder
+	for i := 0; i < len(command); i++ {
+		if command[i] == '\\' && i+1 < len(command) {
+			switch command[i+1] {
+			case 'n':
+				b.WriteByte('\n')
+				i++
+				continue
+			case '\\':
+				b.WriteByte('\\')
+				i++
+				continue
+			}
+		}
+		b.WriteByte(command[i])
+	}
+	return b.String()
+}
+
+// parseEpoch parses a Unix timestamp, returning the zero time on failure
+func parseEpoch(value string) time.Time {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// containsString checks if a slice contains the given string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"gopkg.in/yaml.v3"
+)
+
+// CheatImporter reads cheat cheatsheets, one snippet per file
+type CheatImporter struct{}
+
+type cheatFrontMatter struct {
+	Syntax string   `yaml:"syntax"`
+	Tags   []string `yaml:"tags"`
+}
+
+// Import reads a cheatsheet or a directory of cheatsheets
+func (c *CheatImporter) Import(path string) ([]snippet.Snippet, error) {
+	files, err := collectFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cheatsheets: %w", err)
+	}
+
+	root := path
+	if len(files) == 1 && files[0] == path {
+		root = filepath.Dir(path)
+	}
+
+	var snippets []snippet.Snippet
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		meta, body, err := splitFrontMatter(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		var front cheatFrontMatter
+		if meta != "" {
+			if err := yaml.Unmarshal([]byte(meta), &front); err != nil {
+				return nil, fmt.Errorf("failed to parse front matter of %s: %w", file, err)
+			}
+		}
+
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			rel = filepath.Base(file)
+		}
+		// Subdirectories become namespaces
+		name := snippet.SlugifyName(filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel))))
+
+		language := front.Syntax
+		if language == "" || language == "sh" {
+			language = "bash"
+		}
+
+		body = strings.TrimSpace(body)
+		s := snippet.NewSnippet(name, cheatDescription(body, name), body, language, front.Tags)
+		snippets = append(snippets, s)
+	}
+
+	return snippets, nil
+}
+
+// splitFrontMatter separates a leading --- delimited YAML block from the body
+func splitFrontMatter(content string) (meta, body string, err error) {
+	if !strings.HasPrefix(content, "---\n") {
+		return "", content, nil
+	}
+	rest := content[len("---\n"):]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return "", "", fmt.Errorf("unterminated front matter")
+	}
+	body = rest[end+len("\n---"):]
+	body = strings.TrimPrefix(body, "\n")
+	return rest[:end], body, nil
+}
+
+// cheatDescription uses the first comment line of a cheatsheet as its description
+func cheatDescription(body, name string) string {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			return strings.TrimSpace(strings.TrimLeft(line, "#"))
+		}
+		if line != "" {
+			break
+		}
+	}
+	return "Cheatsheet for " + name
+}
@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"gopkg.in/yaml.v3"
+)
+
+// EspansoImporter reads espanso match files
+type EspansoImporter struct{}
+
+type espansoFile struct {
+	Matches []struct {
+		Trigger  string   `yaml:"trigger"`
+		Triggers []string `yaml:"triggers"`
+		Replace  string   `yaml:"replace"`
+		Label    string   `yaml:"label"`
+		Vars     []struct {
+			Name   string                 `yaml:"name"`
+			Type   string                 `yaml:"type"`
+			Params map[string]interface{} `yaml:"params"`
+		} `yaml:"vars"`
+	} `yaml:"matches"`
+}
+
+// Import reads a match file or a directory of them
+func (e *EspansoImporter) Import(path string) ([]snippet.Snippet, error) {
+	files, err := collectFiles(path, ".yml", ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read espanso matches: %w", err)
+	}
+
+	var snippets []snippet.Snippet
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var parsed espansoFile
+		if err := yaml.Unmarshal(data, &parsed); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		for _, match := range parsed.Matches {
+			if match.Replace == "" {
+				// Image, form and HTML matches have no text to import
+				continue
+			}
+
+			trigger := match.Trigger
+			if trigger == "" && len(match.Triggers) > 0 {
+				trigger = match.Triggers[0]
+			}
+
+			description := match.Label
+			if description == "" {
+				description = "espanso " + trigger
+			}
+
+			s := newSnippet([]string{strings.Trim(trigger, ":;"), match.Label}, description, match.Replace, "", []string{"espanso"})
+
+			// espanso already uses {{name}} variables
+			for _, v := range match.Vars {
+				p := snippet.Placeholder{Name: v.Name}
+				if v.Type != "" {
+					p.Description = "espanso " + v.Type + " variable"
+				}
+				if echo, ok := v.Params["echo"].(string); ok {
+					p.Default = echo
+				}
+				addPlaceholder(&s, p)
+			}
+
+			snippets = append(snippets, s)
+		}
+	}
+
+	return snippets, nil
+}
@@ -0,0 +1,114 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/snippet"
+)
+
+// Importer converts another tool's snippet collection into snippets
+type Importer interface {
+	Import(path string) ([]snippet.Snippet, error)
+}
+
+// Formats lists the supported import formats
+var Formats = []string{"pet", "navi", "cheat", "vscode", "espanso"}
+
+// New returns the importer for the given format
+func New(format string) (Importer, error) {
+	switch format {
+	case "pet":
+		return &PetImporter{}, nil
+	case "navi":
+		return &NaviImporter{}, nil
+	case "cheat":
+		return &CheatImporter{}, nil
+	case "vscode":
+		return &VSCodeImporter{}, nil
+	case "espanso":
+		return &EspansoImporter{}, nil
+	}
+	return nil, fmt.Errorf("unsupported format '%s' (supported: %s)", format, strings.Join(Formats, ", "))
+}
+
+// newSnippet creates an imported snippet, deriving the name from the first non-empty candidate
+func newSnippet(nameCandidates []string, description, command, language string, tags []string) snippet.Snippet {
+	name := ""
+	for _, candidate := range nameCandidates {
+		if strings.TrimSpace(candidate) != "" {
+			name = snippet.Slugify(candidate)
+			break
+		}
+	}
+	if name == "" {
+		name = snippet.Slugify(firstWords(command, 4))
+	}
+	return snippet.NewSnippet(name, description, command, language, tags)
+}
+
+// firstWords returns up to n whitespace separated words of the text
+func firstWords(text string, n int) string {
+	words := strings.Fields(text)
+	if len(words) > n {
+		words = words[:n]
+	}
+	return strings.Join(words, " ")
+}
+
+// addPlaceholder records a placeholder on the snippet unless it already exists
+func addPlaceholder(s *snippet.Snippet, p snippet.Placeholder) {
+	if existing := s.Placeholder(p.Name); existing != nil {
+		if existing.Default == "" {
+			existing.Default = p.Default
+		}
+		return
+	}
+	s.Placeholders = append(s.Placeholders, p)
+}
+
+// collectFiles returns the path itself or, for a directory, all files below it with one of the extensions
+func collectFiles(path string, extensions ...string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if len(extensions) == 0 {
+			if !strings.HasPrefix(d.Name(), ".") {
+				files = append(files, p)
+			}
+			return nil
+		}
+		for _, ext := range extensions {
+			if strings.EqualFold(filepath.Ext(p), ext) {
+				files = append(files, p)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/snippet"
+)
+
+// NaviImporter reads navi .cheat files
+type NaviImporter struct{}
+
+// naviVarPattern matches <variable> references in navi commands
+var naviVarPattern = regexp.MustCompile(`<([A-Za-z_][A-Za-z0-9_-]*)>`)
+
+// Import reads snippets from a .cheat file or a directory of them
+func (n *NaviImporter) Import(path string) ([]snippet.Snippet, error) {
+	files, err := collectFiles(path, ".cheat")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read navi cheats: %w", err)
+	}
+
+	var snippets []snippet.Snippet
+	for _, file := range files {
+		parsed, err := n.parseFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		snippets = append(snippets, parsed...)
+	}
+	return snippets, nil
+}
+
+// parseFile parses one .cheat file
+func (n *NaviImporter) parseFile(path string) ([]snippet.Snippet, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		snippets    []snippet.Snippet
+		tags        []string
+		description string
+		command     []string
+		variables   = make(map[string]string)
+	)
+
+	flush := func() {
+		if len(command) == 0 {
+			return
+		}
+		s := newSnippet([]string{description}, description, strings.Join(command, "\n"), "", append([]string(nil), tags...))
+		snippets = append(snippets, s)
+		description, command = "", nil
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "%"):
+			flush()
+			tags = nil
+			for _, tag := range strings.Split(strings.TrimPrefix(trimmed, "%"), ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					tags = append(tags, tag)
+				}
+			}
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			description = strings.TrimSpace(strings.TrimPrefix(trimmed, "#"))
+		case strings.HasPrefix(trimmed, ";"), strings.HasPrefix(trimmed, "@"):
+			// Comments and extends are not supported
+		case strings.HasPrefix(trimmed, "$"):
+			flush()
+			name, source, ok := strings.Cut(strings.TrimPrefix(trimmed, "$"), ":")
+			if ok {
+				source, _, _ = strings.Cut(source, "---")
+				variables[strings.TrimSpace(name)] = strings.TrimSpace(source)
+			}
+		default:
+			command = append(command, line)
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Convert <variable> references to placeholders
+	for i := range snippets {
+		s := &snippets[i]
+		s.Command = naviVarPattern.ReplaceAllStringFunc(s.Command, func(ref string) string {
+			name := naviVarPattern.FindStringSubmatch(ref)[1]
+			p := snippet.Placeholder{Name: name}
+			if source := variables[name]; source != "" {
+				p.Description = "values from: " + source
+			}
+			addPlaceholder(s, p)
+			return "{{" + name + "}}"
+		})
+	}
+
+	return snippets, nil
+}
@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+)
+
+// PetImporter reads pet's snippet.toml
+type PetImporter struct{}
+
+// petParamPattern matches <param> and <param=default> parameters
+var petParamPattern = regexp.MustCompile(`<([A-Za-z_][A-Za-z0-9_-]*)(?:=([^<>]*))?>`)
+
+type petFile struct {
+	Snippets []struct {
+		Description string   `toml:"description"`
+		Command     string   `toml:"command"`
+		Tag         []string `toml:"tag"`
+		Output      string   `toml:"output"`
+	} `toml:"snippets"`
+}
+
+// Import reads snippets from a pet TOML file
+func (p *PetImporter) Import(path string) ([]snippet.Snippet, error) {
+	var file petFile
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse pet snippets: %w", err)
+	}
+
+	var snippets []snippet.Snippet
+	for _, entry := range file.Snippets {
+		s := newSnippet([]string{entry.Description}, entry.Description, "", "", entry.Tag)
+		s.Command = petParamPattern.ReplaceAllStringFunc(entry.Command, func(param string) string {
+			match := petParamPattern.FindStringSubmatch(param)
+			addPlaceholder(&s, snippet.Placeholder{
+				Name:    match[1],
+				Default: petDefault(match[2]),
+			})
+			return "{{" + match[1] + "}}"
+		})
+		snippets = append(snippets, s)
+	}
+
+	return snippets, nil
+}
+
+// petDefault extracts the default value, taking the first option of |_a_||_b_| choices
+func petDefault(value string) string {
+	if strings.HasPrefix(value, "|_") {
+		value = strings.TrimPrefix(value, "|_")
+		if end := strings.Index(value, "_|"); end >= 0 {
+			value = value[:end]
+		}
+	}
+	return value
+}
@@ -0,0 +1,183 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/snippet"
+)
+
+// VSCodeImporter reads VS Code .code-snippets and language snippet JSON files
+type VSCodeImporter struct{}
+
+var (
+	// vscodeTabstopPattern matches ${1:default}, ${1|a,b|}, ${name:default}, ${1} and $1
+	vscodeTabstopPattern = regexp.MustCompile(`\$\{([0-9]+|[A-Za-z_][A-Za-z0-9_]*)(?::([^{}]*)|\|([^|{}]*)[^{}]*\|)?\}|\$([0-9]+)`)
+
+	// trailingCommaPattern matches commas before a closing bracket
+	trailingCommaPattern = regexp.MustCompile(`,(\s*[}\]])`)
+)
+
+type vscodeEntry struct {
+	Prefix      stringList `json:"prefix"`
+	Body        stringList `json:"body"`
+	Description string     `json:"description"`
+	Scope       string     `json:"scope"`
+}
+
+// stringList accepts either a JSON string or an array of strings
+type stringList []string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = stringList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// Import reads a snippets file or a directory of them
+func (v *VSCodeImporter) Import(path string) ([]snippet.Snippet, error) {
+	files, err := collectFiles(path, ".code-snippets", ".json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VS Code snippets: %w", err)
+	}
+
+	var snippets []snippet.Snippet
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var entries map[string]vscodeEntry
+		if err := json.Unmarshal(stripJSONC(data), &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		// Language snippet files are named after their language, e.g. python.json
+		fileLanguage := ""
+		if filepath.Ext(file) == ".json" {
+			fileLanguage = strings.TrimSuffix(filepath.Base(file), ".json")
+		}
+
+		for _, key := range sortedKeys(entries) {
+			snippets = append(snippets, v.convert(key, entries[key], fileLanguage))
+		}
+	}
+
+	return snippets, nil
+}
+
+// convert maps one VS Code snippet onto a snippet
+func (v *VSCodeImporter) convert(key string, entry vscodeEntry, fileLanguage string) snippet.Snippet {
+	language := fileLanguage
+	if entry.Scope != "" {
+		language = strings.TrimSpace(strings.Split(entry.Scope, ",")[0])
+	}
+
+	description := entry.Description
+	if description == "" {
+		description = key
+	}
+
+	var nameCandidates []string
+	if len(entry.Prefix) > 0 {
+		nameCandidates = append(nameCandidates, entry.Prefix[0])
+	}
+	nameCandidates = append(nameCandidates, key)
+
+	s := newSnippet(nameCandidates, description, "", language, []string{"vscode"})
+	s.Command = vscodeTabstopPattern.ReplaceAllStringFunc(strings.Join(entry.Body, "\n"), func(tabstop string) string {
+		match := vscodeTabstopPattern.FindStringSubmatch(tabstop)
+		id, defaultValue := match[1], match[2]
+		if id == "" {
+			id = match[4]
+		}
+		if match[3] != "" {
+			defaultValue = strings.Split(match[3], ",")[0]
+		}
+
+		// $0 marks the final cursor position
+		if id == "0" {
+			return ""
+		}
+
+		name := id
+		if name[0] >= '0' && name[0] <= '9' {
+			name = "arg" + id
+		}
+		addPlaceholder(&s, snippet.Placeholder{Name: name, Default: defaultValue})
+		return "{{" + name + "}}"
+	})
+
+	return s
+}
+
+// stripJSONC removes comments and trailing commas so JSON with comments can be decoded
+func stripJSONC(data []byte) []byte {
+	var out []byte
+	inString, escaped := false, false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			out = append(out, c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := strings.Index(string(data[i+2:]), "*/")
+			if end < 0 {
+				i = len(data)
+			} else {
+				i += end + 3
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+
+	return trailingCommaPattern.ReplaceAll(out, []byte("$1"))
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,170 @@
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/snippet"
+	"gopkg.in/yaml.v3"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// yamlErrorPattern extracts the line from yaml.v3 syntax errors
+var yamlErrorPattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// safeParams are special shell parameters that never expand to more than one word
+var safeParams = map[string]bool{"#": true, "?": true, "$": true, "!": true, "-": true}
+
+// checkDirectives checks the {{...}} directives in text and returns it with every
+// directive masked by a same-length filler, so the language checks see valid syntax
+// and keep their line and column numbers
+func checkDirectives(s *snippet.Snippet, text, language string, isCommand bool, exists func(string) bool, used map[string]bool) ([]Diagnostic, string) {
+	var diagnostics []Diagnostic
+	var masked strings.Builder
+	last := 0
+
+	for _, d := range snippet.FindDirectives(text) {
+		line, column := position(text, d.Start)
+		report := func(severity Severity, rule, message string) {
+			diagnostics = append(diagnostics, Diagnostic{Line: line, Column: column, Severity: severity, Rule: rule, Message: message})
+		}
+
+		if _, _, ok := snippet.ParseReference(d.Text); ok {
+			// References are checked against the allowlist when they are resolved
+		} else if name, ok := snippet.ParseInclude(d.Text); ok {
+			if !isCommand {
+				report(SeverityWarning, "include-in-file", fmt.Sprintf("include of '%s' is only expanded in the command", name))
+			} else if exists != nil && !exists(name) {
+				report(SeverityError, "missing-include", fmt.Sprintf("included snippet '%s' not found", name))
+			}
+		} else if snippet.IsPlaceholderName(d.Text) {
+			used[d.Text] = true
+			if p := s.Placeholder(d.Text); p == nil || p.Default == "" {
+				report(SeverityInfo, "unresolved-placeholder", fmt.Sprintf("placeholder '%s' has no default and must be given on use", d.Text))
+			}
+		} else {
+			report(SeverityWarning, "invalid-directive", fmt.Sprintf("'{{%s}}' is not a placeholder, reference or include and is left as is", d.Text))
+		}
+
+		masked.WriteString(text[last:d.Start])
+		masked.WriteString(filler(language, d.End-d.Start))
+		last = d.End
+	}
+	masked.WriteString(text[last:])
+
+	rest := masked.String()
+	if i := strings.Index(rest, "{{"); i >= 0 {
+		line, column := position(rest, i)
+		diagnostics = append(diagnostics, Diagnostic{Line: line, Column: column, Severity: SeverityWarning, Rule: "unterminated-directive", Message: "'{{' is not closed by '}}'"})
+	}
+
+	return diagnostics, rest
+}
+
+// filler returns a value of the given length that parses in the language
+func filler(language string, length int) string {
+	if strings.ToLower(language) == "json" && length >= 4 {
+		return "null" + strings.Repeat(" ", length-4)
+	}
+	return strings.Repeat("_", length)
+}
+
+// checkShell parses a shell script and flags unquoted expansions in command arguments
+func checkShell(text string, posix bool) []Diagnostic {
+	variant := syntax.LangBash
+	if posix {
+		variant = syntax.LangPOSIX
+	}
+
+	file, err := syntax.NewParser(syntax.Variant(variant)).Parse(strings.NewReader(text), "")
+	if err != nil {
+		var parseErr syntax.ParseError
+		if errors.As(err, &parseErr) {
+			return []Diagnostic{{
+				Line:     int(parseErr.Pos.Line()),
+				Column:   int(parseErr.Pos.Col()),
+				Severity: SeverityError,
+				Rule:     "shell-syntax",
+				Message:  parseErr.Text,
+			}}
+		}
+		return []Diagnostic{{Severity: SeverityError, Rule: "shell-syntax", Message: err.Error()}}
+	}
+
+	var diagnostics []Diagnostic
+	syntax.Walk(file, func(node syntax.Node) bool {
+		call, ok := node.(*syntax.CallExpr)
+		if !ok {
+			return true
+		}
+		for _, arg := range call.Args {
+			for _, part := range arg.Parts {
+				if !splits(part) {
+					continue
+				}
+				expansion := text[part.Pos().Offset():part.End().Offset()]
+				diagnostics = append(diagnostics, Diagnostic{
+					Line:     int(part.Pos().Line()),
+					Column:   int(part.Pos().Col()),
+					Severity: SeverityWarning,
+					Rule:     "unquoted-expansion",
+					Message:  fmt.Sprintf("unquoted %s is subject to word splitting and globbing; use \"%s\"", expansion, expansion),
+				})
+			}
+		}
+		return true
+	})
+	return diagnostics
+}
+
+// splits checks if an unquoted word part may expand to several words
+func splits(part syntax.WordPart) bool {
+	switch p := part.(type) {
+	case *syntax.ParamExp:
+		return !p.Length && !(p.Param != nil && safeParams[p.Param.Value])
+	case *syntax.CmdSubst:
+		return true
+	}
+	return false
+}
+
+// checkYAML parses every document in a YAML stream
+func checkYAML(text string) []Diagnostic {
+	decoder := yaml.NewDecoder(strings.NewReader(text))
+	for {
+		var doc interface{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			d := Diagnostic{Severity: SeverityError, Rule: "yaml-syntax", Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+			if match := yamlErrorPattern.FindStringSubmatch(err.Error()); match != nil {
+				d.Line, _ = strconv.Atoi(match[1])
+				d.Message = match[2]
+			}
+			return []Diagnostic{d}
+		}
+	}
+}
+
+// checkJSON parses a JSON document
+func checkJSON(text string) []Diagnostic {
+	var doc interface{}
+	err := json.Unmarshal([]byte(text), &doc)
+	if err == nil {
+		return nil
+	}
+
+	d := Diagnostic{Severity: SeverityError, Rule: "json-syntax", Message: err.Error()}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		d.Line, d.Column = position(text, int(syntaxErr.Offset))
+	}
+	return []Diagnostic{d}
+}
@@ -0,0 +1,184 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/classify"
+	"github.com/atobaum/snippet-manager/internal/snippet"
+)
+
+// Severity ranks how serious a diagnostic is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Diagnostic is a problem found in a snippet's command or one of its files
+type Diagnostic struct {
+	// File is the bundled file the diagnostic belongs to, or empty for the command
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+}
+
+// Location formats the file, line and column of a diagnostic, e.g. "config.yaml:3:5"
+func (d Diagnostic) Location() string {
+	location := d.File
+	if d.Line > 0 {
+		location = strings.TrimPrefix(fmt.Sprintf("%s:%d", location, d.Line), ":")
+		if d.Column > 0 {
+			location += fmt.Sprintf(":%d", d.Column)
+		}
+	}
+	return location
+}
+
+// Result holds the diagnostics of one snippet
+type Result struct {
+	Name        string       `json:"name"`
+	Language    string       `json:"language"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Count returns the number of diagnostics with the given severity
+func (r Result) Count(severity Severity) int {
+	count := 0
+	for _, d := range r.Diagnostics {
+		if d.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// redactedMessages replace the messages of diagnostics that may quote the source of a secret snippet
+var redactedMessages = map[string]string{
+	"shell-syntax":       "shell syntax error",
+	"unquoted-expansion": "unquoted expansion is subject to word splitting and globbing",
+	"yaml-syntax":        "invalid YAML",
+	"json-syntax":        "invalid JSON",
+}
+
+// Snippets lints the named snippets, or every snippet if no names are given.
+// Secret snippets are linted with their values decrypted; one that cannot be
+// decrypted is reported with a secret-unreadable diagnostic instead.
+func Snippets(svc *snippet.Service, names ...string) ([]Result, error) {
+	snippetsFile, err := svc.LoadSnippets()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		for name := range snippetsFile.Snippets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	exists := func(name string) bool {
+		_, ok := snippetsFile.Resolve(name)
+		return ok
+	}
+
+	results := make([]Result, 0, len(names))
+	for _, name := range names {
+		s, err := svc.RevealSnippet(name)
+		if errors.Is(err, snippet.ErrNotFound) {
+			return nil, err
+		}
+		if err != nil {
+			resolved, _ := snippetsFile.Resolve(name)
+			results = append(results, Result{
+				Name:        resolved,
+				Language:    snippetsFile.Snippets[resolved].Language,
+				Diagnostics: []Diagnostic{{Severity: SeverityError, Rule: "secret-unreadable", Message: err.Error()}},
+			})
+			continue
+		}
+		results = append(results, Lint(s, exists))
+	}
+	return results, nil
+}
+
+// Lint checks a snippet's command and files according to their languages.
+// exists reports whether an included snippet exists; nil skips that check.
+// Syntax diagnostics of secret snippets do not quote the source.
+func Lint(s *snippet.Snippet, exists func(name string) bool) Result {
+	result := Result{Name: s.Name, Language: s.Language, Diagnostics: []Diagnostic{}}
+	used := make(map[string]bool)
+
+	add := func(file string, diagnostics []Diagnostic) {
+		for _, d := range diagnostics {
+			if message, ok := redactedMessages[d.Rule]; ok && s.Secret {
+				d.Message = message
+			}
+			d.File = file
+			result.Diagnostics = append(result.Diagnostics, d)
+		}
+	}
+
+	add("", lintText(s, s.Command, s.Language, true, exists, used))
+	for _, f := range s.Files {
+		language := f.Language
+		if language == "" {
+			language = classify.DetectFileLanguage(f.Name, f.Content)
+		}
+		add(f.Name, lintText(s, f.Content, language, false, exists, used))
+	}
+
+	for _, p := range s.Placeholders {
+		if !used[p.Name] {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				Rule:     "unused-placeholder",
+				Message:  fmt.Sprintf("placeholder '%s' is declared but never used", p.Name),
+			})
+		}
+	}
+
+	return result
+}
+
+// lintText checks the directives and syntax of a command or file, recording used placeholders
+func lintText(s *snippet.Snippet, text, language string, isCommand bool, exists func(string) bool, used map[string]bool) []Diagnostic {
+	diagnostics, masked := checkDirectives(s, text, language, isCommand, exists, used)
+
+	switch strings.ToLower(language) {
+	case "bash", "shell":
+		diagnostics = append(diagnostics, checkShell(masked, false)...)
+	case "sh":
+		diagnostics = append(diagnostics, checkShell(masked, true)...)
+	case "yaml", "yml":
+		diagnostics = append(diagnostics, checkYAML(masked)...)
+	case "json":
+		diagnostics = append(diagnostics, checkJSON(masked)...)
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].Line != diagnostics[j].Line {
+			return diagnostics[i].Line < diagnostics[j].Line
+		}
+		return diagnostics[i].Column < diagnostics[j].Column
+	})
+	return diagnostics
+}
+
+// position converts a byte offset in text to a 1-based line and column
+func position(text string, offset int) (int, int) {
+	if offset > len(text) {
+		offset = len(text)
+	}
+	before := text[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := offset - strings.LastIndex(before, "\n")
+	return line, column
+}
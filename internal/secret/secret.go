@@ -0,0 +1,164 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// prefix marks a value that is encrypted at rest
+	prefix = "sni:enc:v1:"
+
+	saltSize         = 16
+	keySize          = 32
+	pbkdf2Iterations = 600000
+)
+
+// Keyring encrypts and decrypts secret values with a key derived from a passphrase or keyfile
+type Keyring struct {
+	secret     []byte
+	passphrase bool
+	derived    map[string][]byte
+}
+
+// IsEncrypted checks if a value was produced by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// NewKeyring creates a keyring from the first available key source:
+// the SNI_PASSPHRASE environment variable, the file named by SNI_KEYFILE,
+// or the default keyfile.
+func NewKeyring(defaultKeyFile string) (*Keyring, error) {
+	if passphrase := os.Getenv("SNI_PASSPHRASE"); passphrase != "" {
+		return &Keyring{
+			secret:     []byte(passphrase),
+			passphrase: true,
+			derived:    make(map[string][]byte),
+		}, nil
+	}
+
+	keyFile := os.Getenv("SNI_KEYFILE")
+	if keyFile == "" {
+		keyFile = defaultKeyFile
+	}
+
+	data, err := os.ReadFile(keyFile)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no secret key available: set SNI_PASSPHRASE or run 'sni secret keygen'")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyfile: %w", err)
+	}
+
+	secret := []byte(strings.TrimSpace(string(data)))
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("keyfile %s is empty", keyFile)
+	}
+
+	return &Keyring{
+		secret:  secret,
+		derived: make(map[string][]byte),
+	}, nil
+}
+
+// GenerateKeyFile writes a new random keyfile readable only by the owner
+func GenerateKeyFile(path string) error {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create keyfile: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(hex.EncodeToString(key) + "\n"); err != nil {
+		return fmt.Errorf("failed to write keyfile: %w", err)
+	}
+	return nil
+}
+
+// Encrypt seals a plaintext value with AES-GCM under a freshly salted key
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := k.cipher(salt)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := append(append(salt, nonce...), gcm.Seal(nil, nonce, []byte(plaintext), nil)...)
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt. Values that are not encrypted are returned unchanged.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil || len(sealed) < saltSize {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+
+	salt := sealed[:saltSize]
+	gcm, err := k.cipher(salt)
+	if err != nil {
+		return "", err
+	}
+
+	rest := sealed[saltSize:]
+	if len(rest) < gcm.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+
+	plaintext, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: wrong passphrase or keyfile")
+	}
+	return string(plaintext), nil
+}
+
+// cipher derives the key for a salt and returns an AES-GCM cipher
+func (k *Keyring) cipher(salt []byte) (cipher.AEAD, error) {
+	key, ok := k.derived[string(salt)]
+	if !ok {
+		var err error
+		if k.passphrase {
+			key, err = pbkdf2.Key(sha256.New, string(k.secret), salt, pbkdf2Iterations, keySize)
+		} else {
+			key, err = hkdf.Key(sha256.New, k.secret, salt, "sni secret", keySize)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive key: %w", err)
+		}
+		k.derived[string(salt)] = key
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -51,6 +51,9 @@ func (f *FzfSelector) Select(snippets []snippet.Snippet, prompt string) (*snippe
 	for i, s := range snippets {
 		// Show name and description for easier selection
 		line := fmt.Sprintf("%d: %s", i, s.Name)
+		if s.Pinned {
+			line += " ★"
+		}
 		if s.Description != "" {
 			line += fmt.Sprintf(" - %s", s.Description)
 		}
@@ -107,7 +110,11 @@ func (n *NumberSelector) Select(snippets []snippet.Snippet, prompt string) (*sni
 	fmt.Println(prompt)
 	fmt.Println()
 	for i, s := range snippets {
-		fmt.Printf("%d. %s\n", i+1, s.Name)
+		if s.Pinned {
+			fmt.Printf("%d. %s ★\n", i+1, s.Name)
+		} else {
+			fmt.Printf("%d. %s\n", i+1, s.Name)
+		}
 		if s.Description != "" {
 			fmt.Printf("   Description: %s\n", s.Description)
 		}
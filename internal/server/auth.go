@@ -0,0 +1,233 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/config"
+)
+
+const (
+	// tokenSize is the number of random bytes in an API token
+	tokenSize = 32
+
+	// sessionCookie carries the token for the web UI after it logs in
+	sessionCookie = "sni_token"
+)
+
+// access is the level of access a request is granted
+type access int
+
+const (
+	accessNone access = iota
+	accessReadOnly
+	accessFull
+)
+
+// publicPaths are the API endpoints reachable without a token
+var publicPaths = map[string]bool{
+	"/api/login":   true,
+	"/api/logout":  true,
+	"/api/session": true,
+}
+
+// LoadToken reads an API token, generating it first if create is set and the file does not exist.
+// A missing token is returned as "" when create is not set.
+func LoadToken(path string, create bool) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if !create {
+			return "", nil
+		}
+		return GenerateToken(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+// GenerateToken writes a new random token readable only by the owner, replacing any previous one
+func GenerateToken(path string) (string, error) {
+	raw := make([]byte, tokenSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write token file: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(path, 0600); err != nil {
+		return "", fmt.Errorf("failed to set token file mode: %w", err)
+	}
+	return token, nil
+}
+
+// loadTokens reads the server's tokens, generating the full-access token on first run
+func (s *Server) loadTokens(cfg *config.Config) error {
+	token, err := LoadToken(cfg.TokenFile, true)
+	if err != nil {
+		return err
+	}
+	readOnly, err := LoadToken(cfg.ReadOnlyTokenFile, false)
+	if err != nil {
+		return err
+	}
+
+	s.token, s.readOnlyToken = token, readOnly
+	s.allowedOrigins = make(map[string]bool)
+	for _, origin := range cfg.Server.AllowedOrigins {
+		s.allowedOrigins[strings.TrimSuffix(origin, "/")] = true
+	}
+	return nil
+}
+
+// accessFor checks a token against the server's tokens
+func (s *Server) accessFor(token string) access {
+	if token == "" {
+		return accessNone
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1 {
+		return accessFull
+	}
+	if s.readOnlyToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.readOnlyToken)) == 1 {
+		return accessReadOnly
+	}
+	return accessNone
+}
+
+// requestAccess returns the access granted by the bearer token or session cookie of a request
+func (s *Server) requestAccess(r *http.Request) access {
+	if header := r.Header.Get("Authorization"); header != "" {
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			return accessNone
+		}
+		return s.accessFor(strings.TrimSpace(token))
+	}
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		return s.accessFor(cookie.Value)
+	}
+	return accessNone
+}
+
+// authMiddleware requires a valid token for the API. The read-only token only allows GET and HEAD.
+// The UI itself is served without a token so it can show the login form.
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") || publicPaths[r.URL.Path] || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		switch s.requestAccess(r) {
+		case accessFull:
+		case accessReadOnly:
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				http.Error(w, "Read-only token", http.StatusForbidden)
+				return
+			}
+		default:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="sni"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed reports whether a browser origin may call the API:
+// the server's own origin or one listed in server.allowed_origins
+func (s *Server) originAllowed(r *http.Request, origin string) bool {
+	if s.allowedOrigins[origin] {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return parsed.Scheme == scheme && parsed.Host == r.Host
+}
+
+// handleLogin handles POST /api/login, exchanging a token for a session cookie
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	level := s.accessFor(strings.TrimSpace(req.Token))
+	if level == accessNone {
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    strings.TrimSpace(req.Token),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"authenticated": true, "read_only": level == accessReadOnly})
+}
+
+// handleLogout handles POST /api/logout, clearing the session cookie
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	json.NewEncoder(w).Encode(map[string]string{"message": "Logged out"})
+}
+
+// handleSession handles GET /api/session, reporting whether the request is logged in
+func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	level := s.requestAccess(r)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"authenticated": level != accessNone, "read_only": level == accessReadOnly})
+}
@@ -1,45 +1,108 @@
 package server
 
 import (
+	"bytes"
+	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/atobaum/snippet-manager/internal/config"
+	"github.com/atobaum/snippet-manager/internal/exporter"
+	"github.com/atobaum/snippet-manager/internal/lint"
 	"github.com/atobaum/snippet-manager/internal/snippet"
 )
 
 //go:embed all:dist
 var staticFiles embed.FS
 
+const (
+	// eventHeartbeat is how often an idle event stream sends a comment to keep proxies from closing it
+	eventHeartbeat = 25 * time.Second
+	// eventRetry is the reconnect delay suggested to event stream clients
+	eventRetry = 3 * time.Second
+)
+
+const (
+	// Timeouts for reading requests, writing responses and keeping idle connections open
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+	writeTimeout      = 30 * time.Second
+	idleTimeout       = 120 * time.Second
+
+	// shutdownTimeout bounds how long in-flight requests may take to finish after a signal
+	shutdownTimeout = 10 * time.Second
+)
+
+// Options configures the web server
+type Options struct {
+	// Host is the address to bind; empty binds every interface
+	Host string
+	Port int
+	// DevMode proxies the UI to the Svelte dev server
+	DevMode bool
+	// TLSCert and TLSKey serve HTTPS when both are set
+	TLSCert string
+	TLSKey  string
+}
+
 // Server represents the web server
 type Server struct {
 	snippetService *snippet.Service
-	port           int
-	devMode        bool
+	options        Options
+	// shutdown is closed when the server starts shutting down, ending long-lived event streams
+	shutdown chan struct{}
+
+	// token grants full access and readOnlyToken, if set, read-only access to the API
+	token          string
+	readOnlyToken  string
+	tokenFile      string
+	allowedOrigins map[string]bool
 }
 
 // NewServer creates a new web server
-func NewServer(port int, devMode bool) (*Server, error) {
+func NewServer(options Options) (*Server, error) {
+	if (options.TLSCert == "") != (options.TLSKey == "") {
+		return nil, fmt.Errorf("--tls-cert and --tls-key must be given together")
+	}
+
 	svc, err := snippet.NewService()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create snippet service: %w", err)
 	}
 
-	return &Server{
+	cfg, err := config.DefaultConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+
+	s := &Server{
 		snippetService: svc,
-		port:           port,
-		devMode:        devMode,
-	}, nil
+		options:        options,
+		shutdown:       make(chan struct{}),
+		tokenFile:      cfg.TokenFile,
+	}
+	if err := s.loadTokens(cfg); err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
-// Start starts the web server
+// Start starts the web server and blocks until it is stopped by SIGINT or SIGTERM,
+// letting in-flight requests finish before returning
 func (s *Server) Start() error {
 	// Set up routes
 	mux := http.NewServeMux()
@@ -47,9 +110,15 @@ func (s *Server) Start() error {
 	// API routes
 	mux.HandleFunc("/api/snippets", s.handleSnippets)
 	mux.HandleFunc("/api/snippets/", s.handleSnippet)
+	mux.HandleFunc("/api/tags", s.handleTags)
+	mux.HandleFunc("/api/export", s.handleExport)
+	mux.HandleFunc("/api/events", s.handleEvents)
+	mux.HandleFunc("/api/login", s.handleLogin)
+	mux.HandleFunc("/api/logout", s.handleLogout)
+	mux.HandleFunc("/api/session", s.handleSession)
 
 	// Static files handling
-	if s.devMode {
+	if s.options.DevMode {
 		// In dev mode, proxy to Svelte dev server
 		s.setupDevProxy(mux)
 	} else {
@@ -57,11 +126,81 @@ func (s *Server) Start() error {
 		s.setupStaticFiles(mux)
 	}
 
-	fmt.Printf("🚀 Server starting on http://localhost:%d\n", s.port)
-	if s.devMode {
+	// Serve snippets from memory and pick up edits made outside the server
+	done := make(chan struct{})
+	defer close(done)
+	if err := s.snippetService.Watch(done, s.reportReload); err != nil {
+		fmt.Printf("⚠️  Not watching snippets for changes: %v\n", err)
+	}
+
+	httpServer := &http.Server{
+		Addr:              net.JoinHostPort(s.options.Host, strconv.Itoa(s.options.Port)),
+		Handler:           s.corsMiddleware(s.authMiddleware(mux)),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	httpServer.RegisterOnShutdown(func() { close(s.shutdown) })
+
+	listener, err := net.Listen("tcp", httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	scheme := "http"
+	if s.options.TLSCert != "" {
+		scheme = "https"
+	}
+	host := s.options.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		fmt.Println("⚠️  Listening on every network interface; anyone who can reach this port can use the API")
+		host = "localhost"
+	}
+	fmt.Printf("🚀 Server starting on %s://%s\n", scheme, net.JoinHostPort(host, strconv.Itoa(s.options.Port)))
+	fmt.Printf("🔑 Sign in with the API token in %s (shown by 'sni server token')\n", s.tokenFile)
+	if s.readOnlyToken != "" {
+		fmt.Println("👀 A read-only token is enabled")
+	}
+	if s.options.DevMode {
 		fmt.Println("📝 Development mode: Make sure Svelte dev server is running on port 5173")
 	}
-	return http.ListenAndServe(fmt.Sprintf(":%d", s.port), s.corsMiddleware(mux))
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if s.options.TLSCert != "" {
+			serveErr <- httpServer.ServeTLS(listener, s.options.TLSCert, s.options.TLSKey)
+		} else {
+			serveErr <- httpServer.Serve(listener)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	fmt.Println("\n🛑 Shutting down, waiting for in-flight requests...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down cleanly: %w", err)
+	}
+	fmt.Println("👋 Server stopped")
+	return nil
+}
+
+// reportReload logs the outcome of reloading snippets changed on disk
+func (s *Server) reportReload(err error) {
+	if err != nil {
+		fmt.Printf("⚠️  Keeping the previous snippets, the changed data could not be loaded: %v\n", err)
+		return
+	}
+	fmt.Println("🔄 Snippets reloaded from disk")
 }
 
 // setupDevProxy sets up proxy to Svelte dev server for development
@@ -140,12 +279,24 @@ func (s *Server) setupStaticFiles(mux *http.ServeMux) {
 	})
 }
 
-// corsMiddleware adds CORS headers
+// corsMiddleware adds CORS headers for the server's own origin and those in server.allowed_origins.
+// API requests from any other browser origin are rejected.
 func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Add("Vary", "Origin")
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if !s.originAllowed(r, origin) {
+				if strings.HasPrefix(r.URL.Path, "/api/") {
+					http.Error(w, "Origin not allowed", http.StatusForbidden)
+					return
+				}
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Last-Event-ID")
+			}
+		}
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -168,17 +319,53 @@ func (s *Server) handleSnippets(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleSnippet handles GET/PUT/DELETE /api/snippets/{name}
+// snippetActions lists the sub-resources of a snippet by method, e.g. POST /api/snippets/{name}/rename.
+// Every action must be one of snippet.ReservedNames so it cannot be mistaken for the end of a name.
+var snippetActions = map[string][]string{
+	http.MethodGet:    {"lint"},
+	http.MethodPost:   {"rename", "pin"},
+	http.MethodDelete: {"pin"},
+}
+
+// handleSnippet handles GET/PUT/DELETE /api/snippets/{name}, POST /api/snippets/{name}/rename,
+// POST/DELETE /api/snippets/{name}/pin and GET /api/snippets/{name}/lint.
+// Names may contain namespace slashes, either literally or encoded as %2F.
 func (s *Server) handleSnippet(w http.ResponseWriter, r *http.Request) {
-	// Extract snippet name from URL
-	path := strings.TrimPrefix(r.URL.Path, "/api/snippets/")
-	name := strings.Split(path, "/")[0]
+	// Extract snippet name and optional action from URL
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/api/snippets/")
+	action := ""
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		for _, known := range snippetActions[r.Method] {
+			if path[i+1:] == known {
+				path, action = path[:i], known
+				break
+			}
+		}
+	}
+
+	name, err := url.PathUnescape(path)
+	if err != nil {
+		http.Error(w, "Invalid snippet name", http.StatusBadRequest)
+		return
+	}
 
 	if name == "" {
 		http.Error(w, "Snippet name required", http.StatusBadRequest)
 		return
 	}
 
+	switch action {
+	case "rename":
+		s.renameSnippet(w, r, name)
+		return
+	case "pin":
+		s.pinSnippet(w, r, name, r.Method == http.MethodPost)
+		return
+	case "lint":
+		s.lintSnippet(w, r, name)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		s.getSnippet(w, r, name)
@@ -206,11 +393,16 @@ func (s *Server) getSnippets(w http.ResponseWriter, r *http.Request) {
 // createSnippet creates a new snippet
 func (s *Server) createSnippet(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name        string   `json:"name"`
-		Description string   `json:"description"`
-		Language    string   `json:"language"`
-		Command     string   `json:"command"`
-		Tags        []string `json:"tags"`
+		Name         string                `json:"name"`
+		Description  string                `json:"description"`
+		Language     string                `json:"language"`
+		Command      string                `json:"command"`
+		Tags         []string              `json:"tags"`
+		Aliases      []string              `json:"aliases"`
+		Secret       bool                  `json:"secret"`
+		Placeholders []snippet.Placeholder `json:"placeholders"`
+		Files        []snippet.File        `json:"files"`
+		Hooks        []string              `json:"hooks"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -223,7 +415,15 @@ func (s *Server) createSnippet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := s.snippetService.CreateSnippet(req.Name, req.Description, req.Command, req.Language, req.Tags)
+	newSnippet := snippet.NewSnippet(req.Name, req.Description, req.Command, req.Language, req.Tags)
+	newSnippet.Secret = req.Secret
+	newSnippet.Aliases = req.Aliases
+	newSnippet.Placeholders = req.Placeholders
+	newSnippet.Files = req.Files
+	newSnippet.Hooks = req.Hooks
+	newSnippet.ApplyDefaults()
+
+	err := s.snippetService.AddSnippet(newSnippet)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -252,6 +452,11 @@ func (s *Server) updateSnippet(w http.ResponseWriter, r *http.Request, name stri
 		Language    string   `json:"language"`
 		Command     string   `json:"command"`
 		Tags        []string `json:"tags"`
+		Secret      *bool    `json:"secret"`
+		// Aliases, Files and Hooks replace the current values when present
+		Aliases []string       `json:"aliases"`
+		Files   []snippet.File `json:"files"`
+		Hooks   []string       `json:"hooks"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -259,7 +464,20 @@ func (s *Server) updateSnippet(w http.ResponseWriter, r *http.Request, name stri
 		return
 	}
 
-	err := s.snippetService.UpdateSnippet(name, req.Description, req.Command, req.Language, req.Tags)
+	err := s.snippetService.ApplyChanges(name, snippet.SnippetChanges{
+		Description: req.Description,
+		Command:     req.Command,
+		Language:    req.Language,
+		Tags:        req.Tags,
+		Aliases:     req.Aliases,
+		Files:       req.Files,
+		Hooks:       req.Hooks,
+		Secret:      req.Secret,
+	})
+	if errors.Is(err, snippet.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -268,10 +486,82 @@ func (s *Server) updateSnippet(w http.ResponseWriter, r *http.Request, name stri
 	json.NewEncoder(w).Encode(map[string]string{"message": "Snippet updated successfully"})
 }
 
-// deleteSnippet deletes a snippet
-func (s *Server) deleteSnippet(w http.ResponseWriter, r *http.Request, name string) {
-	err := s.snippetService.DeleteSnippet(name)
+// handleTags handles GET /api/tags
+func (s *Server) handleTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tags, err := s.snippetService.TagCounts()
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// pinSnippet pins or unpins a snippet
+func (s *Server) pinSnippet(w http.ResponseWriter, r *http.Request, name string, pinned bool) {
+	if err := s.snippetService.SetPinned(name, pinned); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	message := "Snippet pinned successfully"
+	if !pinned {
+		message = "Snippet unpinned successfully"
+	}
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+// lintSnippet returns the lint diagnostics of a snippet
+func (s *Server) lintSnippet(w http.ResponseWriter, r *http.Request, name string) {
+	results, err := lint.Snippets(s.snippetService, name)
+	if errors.Is(err, snippet.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results[0])
+}
+
+// renameSnippet renames a snippet
+func (s *Server) renameSnippet(w http.ResponseWriter, r *http.Request, name string) {
+	var req struct {
+		Name string `json:"name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	err := s.snippetService.RenameSnippet(name, req.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Snippet renamed successfully"})
+}
+
+// deleteSnippet deletes a snippet, refusing with 409 if other snippets include it unless ?force=true
+func (s *Server) deleteSnippet(w http.ResponseWriter, r *http.Request, name string) {
+	force := r.URL.Query().Get("force") == "true"
+	err := s.snippetService.DeleteSnippet(name, force)
+	switch {
+	case errors.Is(err, snippet.ErrIncluded):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
@@ -279,6 +569,117 @@ func (s *Server) deleteSnippet(w http.ResponseWriter, r *http.Request, name stri
 	json.NewEncoder(w).Encode(map[string]string{"message": "Snippet deleted successfully"})
 }
 
+// handleExport handles GET /api/export?format=&namespace=&tag=&lang=&q=
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+
+	filter := snippet.Filter{
+		Namespace: strings.Trim(query.Get("namespace"), snippet.NamespaceSeparator),
+		Tags:      query["tag"],
+		Language:  query.Get("lang"),
+		Keyword:   query.Get("q"),
+	}
+
+	snippets, err := s.snippetService.SealedSnippets(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !exporter.KeepsIncludes(format) {
+		if snippets, err = s.snippetService.InlineIncludes(snippets); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := exporter.Export(&buf, snippets, format); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", exporter.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exporter.FileName(format)))
+	w.Write(buf.Bytes())
+}
+
+// handleEvents handles GET /api/events, a Server-Sent Events stream of snippet changes.
+// A reconnecting client sends Last-Event-ID and receives the events it missed first.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("lastEventId")
+	}
+	missed, events, unsubscribe, err := s.snippetService.Subscribe(lastID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer unsubscribe()
+
+	// The stream outlives the server's write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetry.Milliseconds())
+	for _, event := range missed {
+		writeEvent(w, event)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.shutdown:
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and replays
+				return
+			}
+			writeEvent(w, event)
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes a snippet event in Server-Sent Events format
+func writeEvent(w io.Writer, event snippet.Event) {
+	data, _ := json.Marshal(event)
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
+
 // handleFallback serves a fallback page when dist folder doesn't exist
 func (s *Server) handleFallback(w http.ResponseWriter, r *http.Request) {
 	html := `<!DOCTYPE html>
@@ -303,7 +704,16 @@ func (s *Server) handleFallback(w http.ResponseWriter, r *http.Request) {
                 <li>GET /api/snippets/{name} - Get specific snippet</li>
                 <li>PUT /api/snippets/{name} - Update snippet</li>
                 <li>DELETE /api/snippets/{name} - Delete snippet</li>
+                <li>POST /api/snippets/{name}/rename - Rename snippet</li>
+                <li>POST/DELETE /api/snippets/{name}/pin - Pin or unpin snippet</li>
+                <li>GET /api/snippets/{name}/lint - Lint snippet</li>
+                <li>GET /api/tags - List tags with snippet counts</li>
+                <li>GET /api/export?format=json|yaml|markdown|vscode|shell - Export snippets</li>
+                <li>GET /api/events - Stream snippet changes (Server-Sent Events)</li>
+                <li>POST /api/login, POST /api/logout, GET /api/session - Web UI sign-in with the API token</li>
             </ul>
+            <p><strong>Authentication:</strong> send <code>Authorization: Bearer &lt;token&gt;</code>
+            with the token shown by <code>sni server token</code>.</p>
             <p><em>Web UI is coming soon... Build the Svelte app first!</em></p>
         </div>
     </div>
@@ -0,0 +1,85 @@
+package snippet
+
+import (
+	"fmt"
+	"time"
+)
+
+// Resolve returns the name of the snippet that has the given name or alias
+func (f *SnippetsFile) Resolve(name string) (string, bool) {
+	if _, exists := f.Snippets[name]; exists {
+		return name, true
+	}
+	for owner, snippet := range f.Snippets {
+		if containsString(snippet.Aliases, name) {
+			return owner, true
+		}
+	}
+	return "", false
+}
+
+// checkAvailable fails if a name is already used as a snippet name or alias
+func (f *SnippetsFile) checkAvailable(name string) error {
+	owner, exists := f.Resolve(name)
+	switch {
+	case !exists:
+		return nil
+	case owner == name:
+		return fmt.Errorf("snippet '%s' already exists", name)
+	default:
+		return fmt.Errorf("'%s' is already an alias of snippet '%s'", name, owner)
+	}
+}
+
+// checkAliases validates the aliases of a snippet against the naming rules and all other names
+func (s *Service) checkAliases(f *SnippetsFile, name string, aliases []string) error {
+	for i, alias := range aliases {
+		if err := s.CheckName(alias); err != nil {
+			return fmt.Errorf("invalid alias: %w", err)
+		}
+		if alias == name {
+			return fmt.Errorf("alias '%s' is the snippet's own name", alias)
+		}
+		if containsString(aliases[:i], alias) {
+			return fmt.Errorf("duplicate alias '%s'", alias)
+		}
+		if owner, exists := f.Resolve(alias); exists && owner != name {
+			if owner == alias {
+				return fmt.Errorf("alias '%s' is the name of another snippet", alias)
+			}
+			return fmt.Errorf("'%s' is already an alias of snippet '%s'", alias, owner)
+		}
+	}
+	return nil
+}
+
+// SetAliases replaces the aliases of a snippet
+func (s *Service) SetAliases(name string, aliases []string) error {
+	snippetsFile, err := s.LoadSnippets()
+	if err != nil {
+		return err
+	}
+
+	resolved, exists := snippetsFile.Resolve(name)
+	if !exists {
+		return fmt.Errorf("snippet '%s' %w", name, ErrNotFound)
+	}
+	if err := s.checkAliases(snippetsFile, resolved, aliases); err != nil {
+		return err
+	}
+
+	snippet := snippetsFile.Snippets[resolved]
+	snippet.Aliases = aliases
+	snippet.UpdatedAt = time.Now()
+	snippetsFile.Snippets[resolved] = snippet
+
+	return s.SaveSnippets(snippetsFile)
+}
+
+// notFoundOrAlias explains why a name that must be a real snippet name was not found
+func notFoundOrAlias(f *SnippetsFile, name string) error {
+	if owner, exists := f.Resolve(name); exists {
+		return fmt.Errorf("'%s' is an alias of snippet '%s'; use the snippet name", name, owner)
+	}
+	return fmt.Errorf("snippet '%s' %w", name, ErrNotFound)
+}
@@ -0,0 +1,201 @@
+package snippet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupTimeFormat formats backup IDs so they sort by time
+const backupTimeFormat = "20060102-150405.000"
+
+// Backup is a copy of the stored snippets taken before they were overwritten
+type Backup struct {
+	ID   string    `json:"id"`
+	Path string    `json:"path"`
+	Time time.Time `json:"time"`
+	Size int64     `json:"size"`
+}
+
+// ListBackups returns the backups of the stored snippets, newest first
+func (s *Service) ListBackups() ([]Backup, error) {
+	entries, err := os.ReadDir(s.config.BackupDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		id, ok := backupID(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		taken, err := time.ParseInLocation(backupTimeFormat, id, time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{
+			ID:   id,
+			Path: filepath.Join(s.config.BackupDir, entry.Name()),
+			Time: taken,
+			Size: info.Size(),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ID > backups[j].ID
+	})
+	return backups, nil
+}
+
+// ReadBackup parses a backup without restoring it
+func (s *Service) ReadBackup(id string) (*SnippetsFile, error) {
+	data, err := s.readBackup(id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSnippetsFile(data)
+}
+
+// RestoreBackup replaces the stored snippets with a backup after checking that it parses.
+// The current snippets are backed up first, so a restore can itself be undone.
+func (s *Service) RestoreBackup(id string) error {
+	data, err := s.readBackup(id)
+	if err != nil {
+		return err
+	}
+	snippetsFile, err := ParseSnippetsFile(data)
+	if err != nil {
+		return fmt.Errorf("backup '%s' is not a valid snippets file: %w", id, err)
+	}
+
+	if err := s.backupSnippets(); err != nil {
+		return err
+	}
+	return s.store.Save(snippetsFile)
+}
+
+// readBackup reads the raw contents of a backup
+func (s *Service) readBackup(id string) ([]byte, error) {
+	if strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("backup '%s' not found", id)
+	}
+	data, err := os.ReadFile(filepath.Join(s.config.BackupDir, backupFileName(id)))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("backup '%s' not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	return data, nil
+}
+
+// backupSnippets copies the stored snippets in snippets.yaml format into the backup directory
+// and removes backups beyond the configured retention
+func (s *Service) backupSnippets() error {
+	return s.backupStore(s.store)
+}
+
+// backupStore backs up the snippets held by a store
+func (s *Service) backupStore(store Store) error {
+	if s.config.Backup.Keep <= 0 {
+		return nil
+	}
+
+	data, err := store.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to read snippets for backup: %w", err)
+	}
+	if data == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.config.BackupDir, 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	path := filepath.Join(s.config.BackupDir, backupFileName(time.Now().Format(backupTimeFormat)))
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	return s.pruneBackups()
+}
+
+// pruneBackups removes the oldest backups beyond the configured count and age
+func (s *Service) pruneBackups() error {
+	backups, err := s.ListBackups()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Time{}
+	if days := s.config.Backup.MaxAgeDays; days > 0 {
+		cutoff = time.Now().AddDate(0, 0, -days)
+	}
+
+	for i, backup := range backups {
+		// The newest backup is always kept
+		if i == 0 || (i < s.config.Backup.Keep && !backup.Time.Before(cutoff)) {
+			continue
+		}
+		if err := os.Remove(backup.Path); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// backupFileName returns the file name of the backup with the given ID
+func backupFileName(id string) string {
+	return "snippets-" + id + ".yaml"
+}
+
+// backupID extracts the backup ID from a backup file name
+func backupID(fileName string) (string, bool) {
+	if !strings.HasPrefix(fileName, "snippets-") || !strings.HasSuffix(fileName, ".yaml") {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(fileName, "snippets-"), ".yaml"), true
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into place,
+// so readers never see a partially written file
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
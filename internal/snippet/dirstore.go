@@ -0,0 +1,315 @@
+package snippet
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/classify"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// frontMatterDelimiter opens and closes the YAML front matter of a snippet file
+	frontMatterDelimiter = "---\n"
+
+	// dirVersionFile records the format version of a snippets directory
+	dirVersionFile = ".version"
+)
+
+// dirStore keeps each snippet in its own file: YAML front matter with every field but the command,
+// followed by the command as the body. Namespaces become subdirectories and the file extension
+// follows the language, e.g. k8s/pod/logs.sh.
+type dirStore struct {
+	dir string
+}
+
+// Load reads every snippet file under the directory. Files without front matter are read
+// as a bare command whose language is detected from the extension.
+func (st *dirStore) Load() (*SnippetsFile, error) {
+	snippetsFile := &SnippetsFile{
+		Version:  CurrentVersion,
+		Snippets: make(map[string]Snippet),
+	}
+	if _, err := os.Stat(st.dir); os.IsNotExist(err) {
+		return snippetsFile, nil
+	}
+	if err := st.checkVersion(); err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]string)
+	err := st.walk(func(rel string) error {
+		data, err := os.ReadFile(filepath.Join(st.dir, rel))
+		if err != nil {
+			return fmt.Errorf("failed to read snippet file: %w", err)
+		}
+
+		name := strings.TrimSuffix(filepath.ToSlash(rel), filepath.Ext(rel))
+		if other, exists := paths[name]; exists {
+			return fmt.Errorf("snippet '%s' is stored in both %s and %s", name, other, rel)
+		}
+		paths[name] = rel
+
+		snippet, err := parseSnippetFile(rel, data)
+		if err != nil {
+			return err
+		}
+		snippetsFile.Snippets[name] = snippet
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snippetsFile, nil
+}
+
+// Save writes a file for every snippet, leaving unchanged files untouched,
+// and removes the files of snippets that no longer exist
+func (st *dirStore) Save(snippetsFile *SnippetsFile) error {
+	wanted := make(map[string][]byte, len(snippetsFile.Snippets))
+	owners := make(map[string]string, len(snippetsFile.Snippets))
+	for name, snippet := range snippetsFile.Snippets {
+		if err := ValidateFileName(name); err != nil || strings.HasPrefix(name, ".") || strings.Contains(name, "/.") {
+			return fmt.Errorf("snippet name '%s' cannot be stored as a file", name)
+		}
+		data, err := formatSnippetFile(snippet)
+		if err != nil {
+			return fmt.Errorf("failed to marshal snippet '%s': %w", name, err)
+		}
+		rel := filepath.FromSlash(name + classify.FileExtension(snippet.Language))
+		wanted[rel] = data
+		owners[rel] = name
+	}
+	if err := checkPathCollisions(owners); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(st.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create snippets directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(st.dir, dirVersionFile), []byte(strconv.Itoa(CurrentVersion)+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write snippets directory version: %w", err)
+	}
+
+	var stale []string
+	err := st.walk(func(rel string) error {
+		if _, ok := wanted[rel]; !ok {
+			stale = append(stale, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, rel := range stale {
+		if err := os.Remove(filepath.Join(st.dir, rel)); err != nil {
+			return fmt.Errorf("failed to remove snippet file: %w", err)
+		}
+	}
+
+	for rel, data := range wanted {
+		path := filepath.Join(st.dir, rel)
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create snippet directory: %w", err)
+		}
+		if err := writeFileAtomic(path, data); err != nil {
+			return fmt.Errorf("failed to write snippet file: %w", err)
+		}
+	}
+
+	return st.removeEmptyDirs()
+}
+
+// checkPathCollisions refuses snippet files whose paths differ only in case, or where one
+// snippet's file would be another's namespace directory. Case-insensitive filesystems, the
+// default on macOS and Windows, would let such snippets overwrite each other.
+func checkPathCollisions(owners map[string]string) error {
+	rels := make([]string, 0, len(owners))
+	for rel := range owners {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	files := make(map[string]string, len(rels))
+	dirs := make(map[string]string)
+	dirOwners := make(map[string]string)
+	for _, rel := range rels {
+		folded := strings.ToLower(rel)
+		if other, ok := files[folded]; ok {
+			return fmt.Errorf("snippets '%s' and '%s' would be stored in the same file on a case-insensitive filesystem; rename one of them", other, owners[rel])
+		}
+		files[folded] = owners[rel]
+		for dir := filepath.Dir(rel); dir != "."; dir = filepath.Dir(dir) {
+			foldedDir := strings.ToLower(dir)
+			if existing, ok := dirs[foldedDir]; !ok {
+				dirs[foldedDir] = dir
+				dirOwners[foldedDir] = owners[rel]
+			} else if existing != dir {
+				return fmt.Errorf("snippets '%s' and '%s' use namespaces that differ only in case, which share a directory on a case-insensitive filesystem; rename one of them", dirOwners[foldedDir], owners[rel])
+			}
+		}
+	}
+	for _, rel := range rels {
+		if other, ok := dirOwners[strings.ToLower(rel)]; ok {
+			return fmt.Errorf("snippet '%s' would be stored where the namespace directory of '%s' is; rename one of them", owners[rel], other)
+		}
+	}
+	return nil
+}
+
+// Snapshot returns the snippets in snippets.yaml format
+func (st *dirStore) Snapshot() ([]byte, error) {
+	if _, err := os.Stat(st.dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	snippetsFile, err := st.Load()
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(snippetsFile)
+}
+
+// checkVersion rejects directories written by a newer version of sni
+func (st *dirStore) checkVersion() error {
+	data, err := os.ReadFile(filepath.Join(st.dir, dirVersionFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snippets directory version: %w", err)
+	}
+
+	version, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return fmt.Errorf("invalid snippets directory version '%s'", strings.TrimSpace(string(data)))
+	}
+	if version > CurrentVersion {
+		return fmt.Errorf("snippets directory version %d is newer than this sni supports (%d); upgrade sni to open it", version, CurrentVersion)
+	}
+	return nil
+}
+
+// walk calls fn with the relative path of every snippet file, skipping hidden files and directories
+func (st *dirStore) walk(fn func(rel string) error) error {
+	return filepath.WalkDir(st.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == st.dir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(st.dir, path)
+		if err != nil {
+			return err
+		}
+		return fn(rel)
+	})
+}
+
+// removeEmptyDirs removes namespace directories left empty by removed snippets
+func (st *dirStore) removeEmptyDirs() error {
+	var dirs []string
+	err := filepath.WalkDir(st.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != st.dir {
+			if strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Deepest directories first, so parents can become empty
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, dir := range dirs {
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+			if err := os.Remove(dir); err != nil {
+				return fmt.Errorf("failed to remove empty directory: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// formatSnippetFile renders a snippet as YAML front matter followed by its command
+func formatSnippetFile(snippet Snippet) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(snippet); err != nil {
+		return nil, err
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "command" {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			break
+		}
+	}
+
+	front, err := yaml.Marshal(&node)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelimiter)
+	buf.Write(front)
+	buf.WriteString(frontMatterDelimiter)
+	buf.WriteString(snippet.Command)
+	return buf.Bytes(), nil
+}
+
+// parseSnippetFile reads a snippet file written by formatSnippetFile or a bare command
+func parseSnippetFile(rel string, data []byte) (Snippet, error) {
+	text := string(data)
+	if !strings.HasPrefix(text, frontMatterDelimiter) {
+		return Snippet{
+			Language: classify.DetectFileLanguage(rel, text),
+			Tags:     []string{},
+			Command:  text,
+		}, nil
+	}
+
+	rest := text[len(frontMatterDelimiter):]
+	var front, body string
+	if strings.HasPrefix(rest, frontMatterDelimiter) {
+		body = rest[len(frontMatterDelimiter):]
+	} else {
+		end := strings.Index(rest, "\n"+frontMatterDelimiter)
+		if end < 0 {
+			return Snippet{}, fmt.Errorf("%s: front matter is not closed by '---'", rel)
+		}
+		front, body = rest[:end+1], rest[end+1+len(frontMatterDelimiter):]
+	}
+
+	var snippet Snippet
+	if err := yaml.Unmarshal([]byte(front), &snippet); err != nil {
+		return Snippet{}, fmt.Errorf("%s: failed to parse front matter: %w", rel, err)
+	}
+	snippet.Command = body
+	return snippet, nil
+}
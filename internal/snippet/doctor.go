@@ -0,0 +1,236 @@
+package snippet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlLinePattern extracts the line number from yaml.v3 error messages
+var yamlLinePattern = regexp.MustCompile(`line (\d+): (.*)`)
+
+// Problem is an issue found in the snippets file by CheckSnippets
+type Problem struct {
+	// Snippet is the affected snippet, or empty for problems with the whole file
+	Snippet string
+	Line    int
+	Column  int
+	Message string
+	// Fixable problems are safe to repair with RepairSnippets
+	Fixable bool
+}
+
+// CheckSnippets inspects the stored snippets for problems that loading rejects or silently accepts.
+// A snippets.yaml file that does not parse is reported as problems with positions rather than an error.
+func (s *Service) CheckSnippets() ([]Problem, error) {
+	st, ok := s.store.(*yamlStore)
+	if !ok {
+		snippetsFile, err := s.store.Load()
+		if err != nil {
+			return []Problem{{Message: err.Error()}}, nil
+		}
+		return checkSnippets(snippetsFile, nil), nil
+	}
+
+	data, err := os.ReadFile(st.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippets file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []Problem{parseProblem(err.Error(), nil)}, nil
+	}
+
+	var snippetsFile SnippetsFile
+	if err := root.Decode(&snippetsFile); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return []Problem{parseProblem(err.Error(), &root)}, nil
+		}
+		problems := make([]Problem, 0, len(typeErr.Errors))
+		for _, message := range typeErr.Errors {
+			problems = append(problems, parseProblem(message, &root))
+		}
+		return problems, nil
+	}
+
+	if snippetsFile.Version > CurrentVersion {
+		return []Problem{{Message: fmt.Sprintf("format version %d is newer than this sni supports (%d)", snippetsFile.Version, CurrentVersion)}}, nil
+	}
+
+	var problems []Problem
+	if snippetsFile.Version < CurrentVersion {
+		problems = append(problems, Problem{
+			Message: fmt.Sprintf("format version %d is upgraded to %d on the next save", snippetsFile.Version, CurrentVersion),
+			Fixable: true,
+		})
+	}
+	return append(problems, checkSnippets(&snippetsFile, snippetKeyNodes(&root))...), nil
+}
+
+// checkSnippets checks loaded snippets, positioning problems at their key nodes when known
+func checkSnippets(snippetsFile *SnippetsFile, keys map[string]*yaml.Node) []Problem {
+	var problems []Problem
+	report := func(name, message string, fixable bool) {
+		p := Problem{Snippet: name, Message: message, Fixable: fixable}
+		if node := keys[name]; node != nil {
+			p.Line, p.Column = node.Line, node.Column
+		}
+		problems = append(problems, p)
+	}
+
+	byFold := make(map[string][]string)
+	for name, snippet := range snippetsFile.Snippets {
+		folded := strings.ToLower(name)
+		byFold[folded] = append(byFold[folded], name)
+
+		if strings.TrimSpace(snippet.Command) == "" && len(snippet.Files) == 0 {
+			report(name, "command is empty", false)
+		}
+		if snippet.Name != "" && snippet.Name != name {
+			report(name, fmt.Sprintf("name field '%s' does not match its key", snippet.Name), true)
+		}
+		if snippet.CreatedAt.IsZero() {
+			report(name, "created_at is missing", true)
+		}
+		if endsInReserved(name) {
+			report(name, "name ends in an action word the web API cannot tell apart; rename it", false)
+		}
+	}
+
+	for _, names := range byFold {
+		if len(names) > 1 {
+			sort.Strings(names)
+			report(names[0], fmt.Sprintf("names differ only in case: %s", strings.Join(names, ", ")), false)
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		if problems[i].Snippet != problems[j].Snippet {
+			return problems[i].Snippet < problems[j].Snippet
+		}
+		return problems[i].Message < problems[j].Message
+	})
+	return problems
+}
+
+// RepairSnippets applies the safe repairs for fixable problems and returns how many snippets changed:
+// an older file format is upgraded, name fields are set to their keys
+// and missing timestamps are filled in
+func (s *Service) RepairSnippets() (int, error) {
+	snippetsFile, err := s.LoadSnippets()
+	if err != nil {
+		return 0, err
+	}
+
+	// Snippets without timestamps are dated by the last change to the file
+	fallback := time.Now()
+	if st, ok := s.store.(*yamlStore); ok {
+		if info, err := os.Stat(st.path); err == nil {
+			fallback = info.ModTime()
+		}
+	}
+
+	repaired := 0
+	for name, snippet := range snippetsFile.Snippets {
+		changed := false
+		if snippet.Name != "" && snippet.Name != name {
+			snippet.Name = name
+			changed = true
+		}
+		if snippet.CreatedAt.IsZero() {
+			snippet.CreatedAt = snippet.UpdatedAt
+			if snippet.CreatedAt.IsZero() {
+				snippet.CreatedAt = fallback
+			}
+			if snippet.UpdatedAt.IsZero() {
+				snippet.UpdatedAt = snippet.CreatedAt
+			}
+			changed = true
+		}
+		if changed {
+			snippetsFile.Snippets[name] = snippet
+			repaired++
+		}
+	}
+
+	if repaired == 0 && !s.outdated() {
+		return 0, nil
+	}
+	return repaired, s.SaveSnippets(snippetsFile)
+}
+
+// outdated reports whether snippets.yaml is stored in an older format version
+func (s *Service) outdated() bool {
+	st, ok := s.store.(*yamlStore)
+	if !ok {
+		return false
+	}
+	version, _, err := st.storedVersion()
+	return err == nil && version < CurrentVersion
+}
+
+// parseProblem turns a yaml.v3 error message into a problem, taking the column
+// from the node on the reported line when the document could be read
+func parseProblem(message string, root *yaml.Node) Problem {
+	p := Problem{Message: strings.TrimPrefix(message, "yaml: ")}
+	if match := yamlLinePattern.FindStringSubmatch(message); match != nil {
+		p.Line, _ = strconv.Atoi(match[1])
+		p.Message = match[2]
+		if node := nodeOnLine(root, p.Line); node != nil {
+			p.Column = node.Column
+		}
+	}
+	return p
+}
+
+// nodeOnLine returns the last node in document order that starts on the given line,
+// which for "key: value" is the value that failed to decode
+func nodeOnLine(node *yaml.Node, line int) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	var found *yaml.Node
+	if node.Kind != yaml.DocumentNode && node.Line == line {
+		found = node
+	}
+	for _, child := range node.Content {
+		if match := nodeOnLine(child, line); match != nil {
+			found = match
+		}
+	}
+	return found
+}
+
+// snippetKeyNodes maps snippet names to their key nodes under the top-level snippets mapping
+func snippetKeyNodes(root *yaml.Node) map[string]*yaml.Node {
+	keys := make(map[string]*yaml.Node)
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return keys
+	}
+	top := root.Content[0].Content
+	for i := 0; i+1 < len(top); i += 2 {
+		if top[i].Value != "snippets" || top[i+1].Kind != yaml.MappingNode {
+			continue
+		}
+		entries := top[i+1].Content
+		for j := 0; j+1 < len(entries); j += 2 {
+			keys[entries[j].Value] = entries[j]
+		}
+	}
+	return keys
+}
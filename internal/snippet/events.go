@@ -0,0 +1,187 @@
+package snippet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Event types published when the stored snippets change
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+	// EventReset tells a subscriber that events were missed and every snippet should be reloaded
+	EventReset = "reset"
+)
+
+const (
+	// eventHistory is the number of recent events kept for replay to reconnecting subscribers
+	eventHistory = 256
+	// subscriberBuffer is the number of events a subscriber may fall behind before it is dropped
+	subscriberBuffer = 64
+)
+
+// Event describes a change to a stored snippet
+type Event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	// Revision identifies the stored content of the snippet after the change; empty when deleted
+	Revision string `json:"revision,omitempty"`
+}
+
+// eventLog numbers events, keeps the most recent ones for replay and fans them out to subscribers.
+// Event IDs are "<epoch>-<seq>", so IDs handed out before a restart are recognized as unknown.
+type eventLog struct {
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	recent      []Event
+	subscribers map[chan Event]struct{}
+}
+
+// newEventLog creates an empty event log
+func newEventLog() *eventLog {
+	return &eventLog{
+		epoch:       strconv.FormatInt(time.Now().UnixMilli(), 36),
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// publish numbers the events and sends them to every subscriber.
+// Subscribers that fall too far behind are dropped; they can reconnect and replay.
+func (l *eventLog) publish(events []Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, event := range events {
+		l.seq++
+		event.ID = fmt.Sprintf("%s-%d", l.epoch, l.seq)
+
+		l.recent = append(l.recent, event)
+		if len(l.recent) > eventHistory {
+			l.recent = l.recent[len(l.recent)-eventHistory:]
+		}
+
+		for ch := range l.subscribers {
+			select {
+			case ch <- event:
+			default:
+				delete(l.subscribers, ch)
+				close(ch)
+			}
+		}
+	}
+}
+
+// subscribe registers a subscriber and returns the events it missed after lastID.
+// An unknown or expired lastID is answered with a single reset event.
+func (l *eventLog) subscribe(lastID string) ([]Event, chan Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ch := make(chan Event, subscriberBuffer)
+	l.subscribers[ch] = struct{}{}
+
+	if lastID == "" {
+		return nil, ch
+	}
+	return l.since(lastID), ch
+}
+
+// unsubscribe removes a subscriber
+func (l *eventLog) unsubscribe(ch chan Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.subscribers[ch]; ok {
+		delete(l.subscribers, ch)
+		close(ch)
+	}
+}
+
+// since returns the events after lastID, or a reset event if they are no longer known
+func (l *eventLog) since(lastID string) []Event {
+	current := fmt.Sprintf("%s-%d", l.epoch, l.seq)
+	reset := []Event{{ID: current, Type: EventReset}}
+
+	epoch, seqText, ok := strings.Cut(lastID, "-")
+	if !ok || epoch != l.epoch {
+		return reset
+	}
+	seq, err := strconv.ParseUint(seqText, 10, 64)
+	if err != nil || seq > l.seq {
+		return reset
+	}
+	missed := int(l.seq - seq)
+	if missed == 0 {
+		return nil
+	}
+	if missed > len(l.recent) {
+		return reset
+	}
+	return append([]Event(nil), l.recent[len(l.recent)-missed:]...)
+}
+
+// Subscribe streams snippet changes made through this service or picked up by Watch.
+// It returns the events missed since lastEventID (a reset event if they are no longer known),
+// a channel of new events that is closed if the subscriber falls behind, and a function
+// that ends the subscription.
+func (s *Service) Subscribe(lastEventID string) ([]Event, <-chan Event, func(), error) {
+	if s.events == nil {
+		return nil, nil, nil, fmt.Errorf("snippet changes are only published while watching the store")
+	}
+	missed, ch := s.events.subscribe(lastEventID)
+	return missed, ch, func() { s.events.unsubscribe(ch) }, nil
+}
+
+// revisions returns the revision of every snippet in a collection
+func revisions(snippetsFile *SnippetsFile) map[string]string {
+	revs := make(map[string]string, len(snippetsFile.Snippets))
+	for name, snippet := range snippetsFile.Snippets {
+		revs[name] = revision(snippet)
+	}
+	return revs
+}
+
+// revision hashes the stored form of a snippet
+func revision(snippet Snippet) string {
+	data, err := yaml.Marshal(snippet)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// diffRevisions lists the changes between two sets of revisions, sorted by name
+func diffRevisions(old, new map[string]string) []Event {
+	var events []Event
+	for name, rev := range new {
+		oldRev, existed := old[name]
+		switch {
+		case !existed:
+			events = append(events, Event{Type: EventCreated, Name: name, Revision: rev})
+		case oldRev != rev:
+			events = append(events, Event{Type: EventUpdated, Name: name, Revision: rev})
+		}
+	}
+	for name := range old {
+		if _, exists := new[name]; !exists {
+			events = append(events, Event{Type: EventDeleted, Name: name})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Name < events[j].Name
+	})
+	return events
+}
@@ -0,0 +1,138 @@
+package snippet
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ValidateFileName checks that a bundled file name is a relative path inside the snippet
+func ValidateFileName(name string) error {
+	if name == "" {
+		return fmt.Errorf("file name is required")
+	}
+	if strings.Contains(name, `\`) {
+		return fmt.Errorf("file name '%s' must use / as separator", name)
+	}
+	if path.IsAbs(name) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return fmt.Errorf("file name '%s' must be a relative path", name)
+	}
+
+	cleaned := path.Clean(name)
+	if cleaned != name || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return fmt.Errorf("file name '%s' must not contain '.', '..' or empty segments", name)
+	}
+	return nil
+}
+
+// ValidateFiles checks the names of bundled files and rejects duplicates
+func ValidateFiles(files []File) error {
+	seen := make(map[string]bool)
+	for _, f := range files {
+		if err := ValidateFileName(f.Name); err != nil {
+			return err
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("duplicate file '%s'", f.Name)
+		}
+		seen[f.Name] = true
+	}
+	return nil
+}
+
+// SetFiles replaces the bundled files of a snippet.
+// Files whose content is SecretMask keep their stored content.
+func (s *Service) SetFiles(name string, files []File) error {
+	if err := ValidateFiles(files); err != nil {
+		return err
+	}
+
+	return s.ModifySnippet(name, func(snippet *Snippet) error {
+		replaceFiles(snippet, files)
+		return nil
+	})
+}
+
+// replaceFiles sets the bundled files of a snippet, keeping the stored content of files sent as SecretMask
+func replaceFiles(snippet *Snippet, files []File) {
+	updated := make([]File, len(files))
+	for i, f := range files {
+		if existing := snippet.File(f.Name); existing != nil && f.Content == SecretMask {
+			f.Content = existing.Content
+		}
+		updated[i] = f
+	}
+	snippet.Files = updated
+}
+
+// SetHooks replaces the commands run after a snippet is scaffolded
+func (s *Service) SetHooks(name string, hooks []string) error {
+	return s.ModifySnippet(name, func(snippet *Snippet) error {
+		snippet.Hooks = hooks
+		return nil
+	})
+}
+
+// WriteFiles writes bundled files below dir, creating directories as needed,
+// and returns the paths written. Existing files are only replaced with force.
+func WriteFiles(dir string, files []File, force bool) ([]string, error) {
+	if err := ValidateFiles(files); err != nil {
+		return nil, err
+	}
+
+	if !force {
+		for _, f := range files {
+			target := filepath.Join(dir, filepath.FromSlash(f.Name))
+			if _, err := os.Stat(target); err == nil {
+				return nil, fmt.Errorf("%s already exists (use --force to overwrite)", target)
+			}
+		}
+	}
+
+	var written []string
+	for _, f := range files {
+		target := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return written, fmt.Errorf("failed to create directory for %s: %w", target, err)
+		}
+		if err := os.WriteFile(target, []byte(f.Content), 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		written = append(written, target)
+	}
+	return written, nil
+}
+
+// RenderFiles renders placeholders in the names and contents of the bundled files
+func (s *Snippet) RenderFiles(opts RenderOptions) ([]File, error) {
+	rendered := make([]File, len(s.Files))
+	for i, f := range s.Files {
+		name, err := s.RenderText(f.Name, opts)
+		if err != nil {
+			return nil, err
+		}
+		content, err := s.RenderText(f.Content, opts)
+		if err != nil {
+			return nil, err
+		}
+		rendered[i] = File{Name: name, Language: f.Language, Content: content}
+	}
+
+	// Placeholder values must not move files outside the target directory
+	if err := ValidateFiles(rendered); err != nil {
+		return nil, err
+	}
+	return rendered, nil
+}
+
+// FilePlaceholderNames returns the placeholders used in file names, file contents and hooks
+func (s *Snippet) FilePlaceholderNames() []string {
+	var texts []string
+	for _, f := range s.Files {
+		texts = append(texts, f.Name, f.Content)
+	}
+	texts = append(texts, s.Hooks...)
+	return PlaceholderNames(strings.Join(texts, "\n"))
+}
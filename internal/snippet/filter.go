@@ -0,0 +1,71 @@
+package snippet
+
+import (
+	"strings"
+)
+
+// Filter selects snippets by namespace, tag, language, keyword and pin. Empty fields match everything.
+type Filter struct {
+	// Namespace limits the result to snippets inside it, such as k8s/
+	Namespace string
+	// Tags must all be present on the snippet
+	Tags     []string
+	Language string
+	Keyword  string
+	// Pinned limits the result to pinned snippets
+	Pinned bool
+}
+
+// Match reports whether the snippet satisfies the filter
+func (f Filter) Match(s Snippet) bool {
+	if !InNamespace(s.Name, f.Namespace) {
+		return false
+	}
+
+	for _, tag := range f.Tags {
+		if !hasTag(s.Tags, tag) {
+			return false
+		}
+	}
+
+	if f.Pinned && !s.Pinned {
+		return false
+	}
+
+	if f.Language != "" && !strings.EqualFold(s.Language, f.Language) {
+		return false
+	}
+
+	if f.Keyword != "" {
+		keyword := strings.ToLower(f.Keyword)
+		if !strings.Contains(strings.ToLower(s.Name), keyword) &&
+			!strings.Contains(strings.ToLower(s.Description), keyword) &&
+			!(!s.Secret && strings.Contains(strings.ToLower(s.Command), keyword)) &&
+			!hasTagContaining(s.Tags, keyword) &&
+			!hasTagContaining(s.Aliases, keyword) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasTag checks if the tags include the given tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTagContaining checks if any tag contains the lowercase keyword
+func hasTagContaining(tags []string, keyword string) bool {
+	for _, tag := range tags {
+		if strings.Contains(strings.ToLower(tag), keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package snippet
+
+import (
+	"fmt"
+	"time"
+)
+
+// ImportPolicy decides what happens when an imported snippet name already exists
+type ImportPolicy string
+
+const (
+	ImportSkip      ImportPolicy = "skip"
+	ImportOverwrite ImportPolicy = "overwrite"
+	ImportRename    ImportPolicy = "rename"
+)
+
+// Import actions reported in ImportResult
+const (
+	ImportCreated     = "created"
+	ImportOverwritten = "overwritten"
+	ImportRenamed     = "renamed"
+	ImportSkipped     = "skipped"
+)
+
+// ImportResult records what happened to one imported snippet
+type ImportResult struct {
+	Name         string
+	OriginalName string
+	Action       string
+	// Duplicate marks a snippet renamed because an earlier snippet in the same import has its name
+	Duplicate bool
+}
+
+// ParseImportPolicy validates a conflict policy name
+func ParseImportPolicy(policy string) (ImportPolicy, error) {
+	switch p := ImportPolicy(policy); p {
+	case ImportSkip, ImportOverwrite, ImportRename:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid conflict policy '%s' (expected skip, overwrite or rename)", policy)
+}
+
+// ImportSnippets adds snippets in one write, resolving name conflicts with the policy.
+// Snippets sharing a name with an earlier one in the same import are always renamed.
+// With dryRun nothing is saved but the results describe what would happen.
+func (s *Service) ImportSnippets(snippets []Snippet, policy ImportPolicy, dryRun bool) ([]ImportResult, error) {
+	snippetsFile, err := s.LoadSnippets()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var results []ImportResult
+	imported := make(map[string]bool)
+
+	for _, snippet := range snippets {
+		result := ImportResult{Name: snippet.Name, OriginalName: snippet.Name, Action: ImportCreated}
+
+		if s.CheckName(snippet.Name) != nil {
+			snippet.Name = SlugifyName(snippet.Name)
+			result.Name = snippet.Name
+		}
+
+		snippet.ApplyDefaults()
+		snippet.Tags = s.NormalizeTags(snippet.Tags)
+
+		if snippet.CreatedAt.IsZero() {
+			snippet.CreatedAt = now
+		}
+		if snippet.UpdatedAt.IsZero() {
+			snippet.UpdatedAt = now
+		}
+
+		if owner, exists := snippetsFile.Resolve(snippet.Name); exists {
+			switch {
+			case imported[owner]:
+				result.Action = ImportRenamed
+				result.Duplicate = true
+				result.Name = uniqueName(snippetsFile, snippet.Name)
+				snippet.Name = result.Name
+			case policy == ImportSkip:
+				result.Action = ImportSkipped
+				results = append(results, result)
+				continue
+			case policy == ImportOverwrite && owner == snippet.Name:
+				result.Action = ImportOverwritten
+				snippet.CreatedAt = snippetsFile.Snippets[owner].CreatedAt
+			default:
+				// Aliases of other snippets are never overwritten
+				result.Action = ImportRenamed
+				result.Name = uniqueName(snippetsFile, snippet.Name)
+				snippet.Name = result.Name
+			}
+		}
+
+		snippetsFile.Snippets[snippet.Name] = snippet
+		imported[snippet.Name] = true
+		results = append(results, result)
+	}
+
+	if dryRun {
+		return results, nil
+	}
+	return results, s.SaveSnippets(snippetsFile)
+}
+
+// uniqueName appends a numeric suffix to name until it is unused
+func uniqueName(snippetsFile *SnippetsFile, name string) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if _, exists := snippetsFile.Resolve(candidate); !exists {
+			return candidate
+		}
+	}
+}
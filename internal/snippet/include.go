@@ -0,0 +1,229 @@
+package snippet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/atobaum/snippet-manager/internal/secret"
+)
+
+// IncludePrefix starts a {{> name}} directive that inlines another snippet
+const IncludePrefix = ">"
+
+// ParseInclude splits a {{> name}} directive into the included snippet name
+func ParseInclude(directive string) (string, bool) {
+	if !strings.HasPrefix(directive, IncludePrefix) {
+		return "", false
+	}
+	name := strings.TrimSpace(strings.TrimPrefix(directive, IncludePrefix))
+	return name, name != ""
+}
+
+// Includes returns the snippets included by a command, in order of first use
+func Includes(command string) []string {
+	var names []string
+	for _, match := range templatePattern.FindAllStringSubmatch(command, -1) {
+		if name, ok := ParseInclude(match[1]); ok && !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// replaceIncludes replaces every {{> name}} directive with the result of replace,
+// leaving other directives exactly as written
+func replaceIncludes(command string, replace func(name string) string) string {
+	return templatePattern.ReplaceAllStringFunc(command, func(token string) string {
+		if name, ok := ParseInclude(templatePattern.FindStringSubmatch(token)[1]); ok {
+			return replace(name)
+		}
+		return token
+	})
+}
+
+// ComposeSnippet retrieves a snippet with secret values decrypted and its includes inlined.
+// Placeholders of included snippets are added unless the snippet defines them itself,
+// and the result is secret if any included snippet is.
+func (s *Service) ComposeSnippet(name string) (*Snippet, error) {
+	snippetsFile, err := s.LoadSnippets()
+	if err != nil {
+		return nil, err
+	}
+	return s.compose(snippetsFile, name, nil, true)
+}
+
+// InlineIncludes expands the includes of stored snippets for exports that cannot refer to other
+// snippets. Nothing is decrypted: a snippet that includes a secret snippet becomes secret itself.
+func (s *Service) InlineIncludes(snippets []Snippet) ([]Snippet, error) {
+	snippetsFile, err := s.LoadSnippets()
+	if err != nil {
+		return nil, err
+	}
+
+	inlined := make([]Snippet, len(snippets))
+	for i, snippet := range snippets {
+		if snippet.Secret || len(Includes(snippet.Command)) == 0 {
+			inlined[i] = snippet
+			continue
+		}
+		composed, err := s.compose(snippetsFile, snippet.Name, nil, false)
+		if err != nil {
+			return nil, err
+		}
+		inlined[i] = *composed
+	}
+	return inlined, nil
+}
+
+// compose expands the includes of a snippet, decrypting secret values if reveal is set;
+// stack holds the snippets being expanded
+func (s *Service) compose(snippetsFile *SnippetsFile, name string, stack []string, reveal bool) (*Snippet, error) {
+	if resolved, exists := snippetsFile.Resolve(name); exists {
+		name = resolved
+	}
+	if containsString(stack, name) {
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(append(stack, name), " -> "))
+	}
+
+	snippet, exists := snippetsFile.Snippets[name]
+	if !exists {
+		if len(stack) > 0 {
+			return nil, fmt.Errorf("snippet '%s' included by '%s' %w", name, stack[len(stack)-1], ErrNotFound)
+		}
+		return nil, fmt.Errorf("snippet '%s' %w", name, ErrNotFound)
+	}
+	snippet.Name = name
+	if reveal {
+		if err := s.reveal(&snippet); err != nil {
+			return nil, err
+		}
+	}
+
+	stack = append(stack, name)
+	var composeErr error
+	snippet.Command = replaceIncludes(snippet.Command, func(included string) string {
+		if composeErr != nil {
+			return ""
+		}
+
+		child, err := s.compose(snippetsFile, included, stack, reveal)
+		if err != nil {
+			composeErr = err
+			return ""
+		}
+
+		snippet.Secret = snippet.Secret || child.Secret
+		for _, p := range child.Placeholders {
+			if snippet.Placeholder(p.Name) == nil {
+				snippet.Placeholders = append(snippet.Placeholders, p)
+			}
+		}
+		return strings.TrimSuffix(child.Command, "\n")
+	})
+	if composeErr != nil {
+		return nil, composeErr
+	}
+
+	return &snippet, nil
+}
+
+// IncludeGraph maps every snippet name to the snippets it includes directly.
+// Secret snippets are only read when they can be decrypted.
+func (s *Service) IncludeGraph() (map[string][]string, error) {
+	snippetsFile, err := s.LoadSnippets()
+	if err != nil {
+		return nil, err
+	}
+	graph, _ := s.includeGraph(snippetsFile)
+	return graph, nil
+}
+
+// includeGraph builds the include graph of loaded snippets. It also returns the sorted names of
+// secret snippets that could not be decrypted, whose includes are missing from the graph.
+func (s *Service) includeGraph(snippetsFile *SnippetsFile) (map[string][]string, []string) {
+	graph := make(map[string][]string, len(snippetsFile.Snippets))
+	var unreadable []string
+	for name, snippet := range snippetsFile.Snippets {
+		command, err := s.decryptCommand(snippet)
+		if err != nil {
+			unreadable = append(unreadable, name)
+		}
+		includes := Includes(command)
+		for i, included := range includes {
+			// Refer to included snippets by name even when included through an alias
+			if resolved, exists := snippetsFile.Resolve(included); exists {
+				includes[i] = resolved
+			}
+		}
+		graph[name] = includes
+	}
+	sort.Strings(unreadable)
+	return graph, unreadable
+}
+
+// Dependents returns the snippets that include the named snippet directly, sorted by name
+func Dependents(graph map[string][]string, name string) []string {
+	var dependents []string
+	for other, includes := range graph {
+		if containsString(includes, name) {
+			dependents = append(dependents, other)
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+// decryptCommand returns the command of a stored snippet, decrypting secret commands
+func (s *Service) decryptCommand(snippet Snippet) (string, error) {
+	if !secret.IsEncrypted(snippet.Command) {
+		return snippet.Command, nil
+	}
+	keyring, err := s.keys()
+	if err != nil {
+		return "", err
+	}
+	return keyring.Decrypt(snippet.Command)
+}
+
+// rewriteIncludes points {{> oldName}} directives in other snippets at newName.
+// It fails without changes if a secret snippet cannot be decrypted, since its includes
+// could not be checked and would be left dangling.
+func (s *Service) rewriteIncludes(snippetsFile *SnippetsFile, oldName, newName string) error {
+	commands := make(map[string]string, len(snippetsFile.Snippets))
+	var unreadable []string
+	for name, snippet := range snippetsFile.Snippets {
+		if name == newName {
+			// The renamed snippet cannot include itself
+			continue
+		}
+		command, err := s.decryptCommand(snippet)
+		if err != nil {
+			unreadable = append(unreadable, name)
+			continue
+		}
+		commands[name] = command
+	}
+	if len(unreadable) > 0 {
+		sort.Strings(unreadable)
+		return fmt.Errorf("cannot update includes of '%s': secret snippet(s) %s could not be decrypted", oldName, strings.Join(unreadable, ", "))
+	}
+
+	for name, command := range commands {
+		snippet := snippetsFile.Snippets[name]
+		if !containsString(Includes(command), oldName) {
+			continue
+		}
+		// Secret commands are stored in plain text here and sealed again on save
+		snippet.Command = replaceIncludes(command, func(included string) string {
+			if included == oldName {
+				included = newName
+			}
+			return "{{" + IncludePrefix + " " + included + "}}"
+		})
+		snippet.UpdatedAt = time.Now()
+		snippetsFile.Snippets[name] = snippet
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package snippet
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MergeConflict describes a change that was made differently on both sides of a merge
+type MergeConflict struct {
+	Snippet    string
+	Field      string
+	Ours       string
+	Theirs     string
+	Resolution string
+}
+
+// String formats the conflict for display
+func (c MergeConflict) String() string {
+	return fmt.Sprintf("%s: %s changed on both sides (ours: %s, theirs: %s) - kept %s",
+		c.Snippet, c.Field, c.Ours, c.Theirs, c.Resolution)
+}
+
+// MergeSnippetsFiles performs a three-way merge of snippet files at the snippet and field level.
+// Non-overlapping changes are combined, tags are unioned, and fields changed on both sides
+// take the value from the side with the newest UpdatedAt. Every such field is reported as a conflict.
+func MergeSnippetsFiles(base, ours, theirs *SnippetsFile) (*SnippetsFile, []MergeConflict) {
+	merged := &SnippetsFile{
+		Version:  CurrentVersion,
+		Snippets: make(map[string]Snippet),
+	}
+	var conflicts []MergeConflict
+
+	for _, name := range mergeNames(base, ours, theirs) {
+		b, inBase := base.Snippets[name]
+		o, inOurs := ours.Snippets[name]
+		t, inTheirs := theirs.Snippets[name]
+
+		switch {
+		case !inOurs && !inTheirs:
+			// Deleted on both sides
+		case inOurs && !inTheirs:
+			if !inBase {
+				merged.Snippets[name] = o
+			} else if !snippetsEqual(b, o) {
+				merged.Snippets[name] = o
+				conflicts = append(conflicts, MergeConflict{
+					Snippet: name, Field: "snippet", Ours: "modified", Theirs: "deleted", Resolution: "ours",
+				})
+			}
+		case !inOurs && inTheirs:
+			if !inBase {
+				merged.Snippets[name] = t
+			} else if !snippetsEqual(b, t) {
+				merged.Snippets[name] = t
+				conflicts = append(conflicts, MergeConflict{
+					Snippet: name, Field: "snippet", Ours: "deleted", Theirs: "modified", Resolution: "theirs",
+				})
+			}
+		default:
+			if !inBase {
+				b = Snippet{}
+			}
+			snippet, fieldConflicts := mergeSnippet(name, b, o, t)
+			merged.Snippets[name] = snippet
+			conflicts = append(conflicts, fieldConflicts...)
+		}
+	}
+
+	return merged, conflicts
+}
+
+// mergeNames returns the sorted union of snippet names in all three files
+func mergeNames(files ...*SnippetsFile) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, f := range files {
+		for name := range f.Snippets {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mergeSnippet merges a snippet that exists on both sides field by field
+func mergeSnippet(name string, base, ours, theirs Snippet) (Snippet, []MergeConflict) {
+	var conflicts []MergeConflict
+
+	// Side with the newest change wins genuine conflicts; ties go to ours
+	preferTheirs := theirs.UpdatedAt.After(ours.UpdatedAt)
+	resolution := "ours"
+	if preferTheirs {
+		resolution = "theirs"
+	}
+
+	merged := ours
+	mv := reflect.ValueOf(&merged).Elem()
+	bv := reflect.ValueOf(base)
+	ov := reflect.ValueOf(ours)
+	tv := reflect.ValueOf(theirs)
+	st := mv.Type()
+
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		switch field.Name {
+		case "Name", "CreatedAt", "UpdatedAt":
+			continue
+		case "Tags":
+			merged.Tags = mergeTags(base.Tags, ours.Tags, theirs.Tags)
+			continue
+		}
+
+		b, o, t := bv.Field(i).Interface(), ov.Field(i).Interface(), tv.Field(i).Interface()
+		switch {
+		case reflect.DeepEqual(o, t), reflect.DeepEqual(t, b):
+			// Keep ours
+		case reflect.DeepEqual(o, b):
+			mv.Field(i).Set(tv.Field(i))
+		default:
+			if preferTheirs {
+				mv.Field(i).Set(tv.Field(i))
+			}
+			conflicts = append(conflicts, MergeConflict{
+				Snippet:    name,
+				Field:      yamlFieldName(field),
+				Ours:       conflictValue(o),
+				Theirs:     conflictValue(t),
+				Resolution: resolution,
+			})
+		}
+	}
+
+	merged.Name = ours.Name
+	if merged.Name == "" {
+		merged.Name = theirs.Name
+	}
+	merged.CreatedAt = earliest(ours.CreatedAt, theirs.CreatedAt)
+	merged.UpdatedAt = ours.UpdatedAt
+	if theirs.UpdatedAt.After(merged.UpdatedAt) {
+		merged.UpdatedAt = theirs.UpdatedAt
+	}
+
+	return merged, conflicts
+}
+
+// mergeTags unions the tags of both sides, dropping tags that either side removed from base
+func mergeTags(base, ours, theirs []string) []string {
+	removed := make(map[string]bool)
+	for _, tag := range base {
+		if !containsString(ours, tag) || !containsString(theirs, tag) {
+			removed[tag] = true
+		}
+	}
+
+	var merged []string
+	for _, tag := range append(append([]string{}, ours...), theirs...) {
+		if removed[tag] || containsString(merged, tag) {
+			continue
+		}
+		merged = append(merged, tag)
+	}
+	return merged
+}
+
+// snippetsEqual reports whether two snippets have identical content
+func snippetsEqual(a, b Snippet) bool {
+	a.Name, b.Name = "", ""
+	return reflect.DeepEqual(a, b)
+}
+
+// containsString checks if a slice contains the given string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// earliest returns the earlier of two non-zero timestamps
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+// yamlFieldName returns the YAML key of a struct field
+func yamlFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// conflictValue formats a field value for a conflict report
+func conflictValue(v interface{}) string {
+	s := []rune(strings.ReplaceAll(fmt.Sprintf("%v", v), "\n", " "))
+	if len(s) > 40 {
+		return fmt.Sprintf("%q", string(s[:40])+"...")
+	}
+	return fmt.Sprintf("%q", string(s))
+}
@@ -0,0 +1,190 @@
+package snippet
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	mergeBaseTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mergeEarlier  = mergeBaseTime.Add(time.Hour)
+	mergeLater    = mergeBaseTime.Add(2 * time.Hour)
+)
+
+// testSnippet builds a snippet for merge tests, created at mergeBaseTime
+func testSnippet(name, description, command string, tags []string, updated time.Time) Snippet {
+	return Snippet{
+		Name:        name,
+		Description: description,
+		Command:     command,
+		Language:    "bash",
+		Tags:        tags,
+		CreatedAt:   mergeBaseTime,
+		UpdatedAt:   updated,
+	}
+}
+
+// testFile builds a snippets file from snippets keyed by name
+func testFile(snippets ...Snippet) *SnippetsFile {
+	file := &SnippetsFile{Version: CurrentVersion, Snippets: make(map[string]Snippet)}
+	for _, s := range snippets {
+		file.Snippets[s.Name] = s
+	}
+	return file
+}
+
+func TestMergeSnippetsFiles(t *testing.T) {
+	base := testSnippet("deploy", "Deploy", "make deploy", []string{"ops", "make"}, mergeBaseTime)
+
+	tests := []struct {
+		name          string
+		base          *SnippetsFile
+		ours          *SnippetsFile
+		theirs        *SnippetsFile
+		want          *SnippetsFile
+		wantConflicts []MergeConflict
+	}{
+		{
+			name:   "unchanged",
+			base:   testFile(base),
+			ours:   testFile(base),
+			theirs: testFile(base),
+			want:   testFile(base),
+		},
+		{
+			name:   "different fields changed on each side",
+			base:   testFile(base),
+			ours:   testFile(testSnippet("deploy", "Deploy to prod", "make deploy", []string{"ops", "make"}, mergeEarlier)),
+			theirs: testFile(testSnippet("deploy", "Deploy", "make deploy ENV=prod", []string{"ops", "make"}, mergeLater)),
+			want:   testFile(testSnippet("deploy", "Deploy to prod", "make deploy ENV=prod", []string{"ops", "make"}, mergeLater)),
+		},
+		{
+			name:   "same change on both sides",
+			base:   testFile(base),
+			ours:   testFile(testSnippet("deploy", "Deploy to prod", "make deploy", []string{"ops", "make"}, mergeEarlier)),
+			theirs: testFile(testSnippet("deploy", "Deploy to prod", "make deploy", []string{"ops", "make"}, mergeLater)),
+			want:   testFile(testSnippet("deploy", "Deploy to prod", "make deploy", []string{"ops", "make"}, mergeLater)),
+		},
+		{
+			name:   "tags are unioned and removals kept",
+			base:   testFile(base),
+			ours:   testFile(testSnippet("deploy", "Deploy", "make deploy", []string{"ops", "make", "prod"}, mergeEarlier)),
+			theirs: testFile(testSnippet("deploy", "Deploy", "make deploy", []string{"ops", "ci"}, mergeLater)),
+			want:   testFile(testSnippet("deploy", "Deploy", "make deploy", []string{"ops", "prod", "ci"}, mergeLater)),
+		},
+		{
+			name:   "conflicting field takes the newer side",
+			base:   testFile(base),
+			ours:   testFile(testSnippet("deploy", "Deploy (ours)", "make deploy", []string{"ops", "make"}, mergeEarlier)),
+			theirs: testFile(testSnippet("deploy", "Deploy (theirs)", "make deploy", []string{"ops", "make"}, mergeLater)),
+			want:   testFile(testSnippet("deploy", "Deploy (theirs)", "make deploy", []string{"ops", "make"}, mergeLater)),
+			wantConflicts: []MergeConflict{
+				{Snippet: "deploy", Field: "description", Ours: `"Deploy (ours)"`, Theirs: `"Deploy (theirs)"`, Resolution: "theirs"},
+			},
+		},
+		{
+			name:   "conflicting field at the same time keeps ours",
+			base:   testFile(base),
+			ours:   testFile(testSnippet("deploy", "Deploy", "make ours", []string{"ops", "make"}, mergeLater)),
+			theirs: testFile(testSnippet("deploy", "Deploy", "make theirs", []string{"ops", "make"}, mergeLater)),
+			want:   testFile(testSnippet("deploy", "Deploy", "make ours", []string{"ops", "make"}, mergeLater)),
+			wantConflicts: []MergeConflict{
+				{Snippet: "deploy", Field: "command", Ours: `"make ours"`, Theirs: `"make theirs"`, Resolution: "ours"},
+			},
+		},
+		{
+			name:   "added on either side",
+			base:   testFile(base),
+			ours:   testFile(base, testSnippet("build", "Build", "make", nil, mergeEarlier)),
+			theirs: testFile(base, testSnippet("test", "Test", "make test", nil, mergeLater)),
+			want: testFile(base,
+				testSnippet("build", "Build", "make", nil, mergeEarlier),
+				testSnippet("test", "Test", "make test", nil, mergeLater)),
+		},
+		{
+			name:   "deleted on one side and unchanged on the other",
+			base:   testFile(base),
+			ours:   testFile(),
+			theirs: testFile(base),
+			want:   testFile(),
+		},
+		{
+			name:   "deleted on both sides",
+			base:   testFile(base),
+			ours:   testFile(),
+			theirs: testFile(),
+			want:   testFile(),
+		},
+		{
+			name:   "deleted by us and modified by them",
+			base:   testFile(base),
+			ours:   testFile(),
+			theirs: testFile(testSnippet("deploy", "Deploy", "make release", []string{"ops", "make"}, mergeLater)),
+			want:   testFile(testSnippet("deploy", "Deploy", "make release", []string{"ops", "make"}, mergeLater)),
+			wantConflicts: []MergeConflict{
+				{Snippet: "deploy", Field: "snippet", Ours: "deleted", Theirs: "modified", Resolution: "theirs"},
+			},
+		},
+		{
+			name:   "modified by us and deleted by them",
+			base:   testFile(base),
+			ours:   testFile(testSnippet("deploy", "Deploy", "make release", []string{"ops", "make"}, mergeEarlier)),
+			theirs: testFile(),
+			want:   testFile(testSnippet("deploy", "Deploy", "make release", []string{"ops", "make"}, mergeEarlier)),
+			wantConflicts: []MergeConflict{
+				{Snippet: "deploy", Field: "snippet", Ours: "modified", Theirs: "deleted", Resolution: "ours"},
+			},
+		},
+		{
+			name:   "added on both sides with different content",
+			base:   testFile(),
+			ours:   testFile(testSnippet("deploy", "Deploy", "make ours", []string{"ops"}, mergeLater)),
+			theirs: testFile(testSnippet("deploy", "Deploy", "make theirs", []string{"ci"}, mergeEarlier)),
+			want:   testFile(testSnippet("deploy", "Deploy", "make ours", []string{"ops", "ci"}, mergeLater)),
+			wantConflicts: []MergeConflict{
+				{Snippet: "deploy", Field: "command", Ours: `"make ours"`, Theirs: `"make theirs"`, Resolution: "ours"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := MergeSnippetsFiles(tt.base, tt.ours, tt.theirs)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merged snippets = %+v, want %+v", got.Snippets, tt.want.Snippets)
+			}
+			if !reflect.DeepEqual(conflicts, tt.wantConflicts) {
+				t.Errorf("conflicts = %v, want %v", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestConflictValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"short", "make deploy", `"make deploy"`},
+		{"newlines", "a\nb", `"a b"`},
+		{"long", strings.Repeat("a", 45), `"` + strings.Repeat("a", 40) + `..."`},
+		{"multi-byte characters", strings.Repeat("한", 45), `"` + strings.Repeat("한", 40) + `..."`},
+		{"slice", []string{"ops", "ci"}, `"[ops ci]"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := conflictValue(tt.value)
+			if got != tt.want {
+				t.Errorf("conflictValue() = %s, want %s", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("conflictValue() = %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
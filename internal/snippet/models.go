@@ -2,22 +2,54 @@ package snippet
 
 import (
 	"time"
+
+	"github.com/atobaum/snippet-manager/internal/classify"
 )
 
+// SecretMask replaces secret values in output
+const SecretMask = "********"
+
 // Snippet represents a single code snippet
 type Snippet struct {
-	Name        string    `yaml:"name,omitempty" json:"name"`
-	Description string    `yaml:"description" json:"description"`
-	Language    string    `yaml:"language,omitempty" json:"language"`
-	Tags        []string  `yaml:"tags" json:"tags"`
-	Command     string    `yaml:"command" json:"command"`
-	CreatedAt   time.Time `yaml:"created_at,omitempty" json:"created_at"`
-	UpdatedAt   time.Time `yaml:"updated_at,omitempty" json:"updated_at"`
+	Name         string        `yaml:"name,omitempty" json:"name"`
+	Description  string        `yaml:"description" json:"description"`
+	Language     string        `yaml:"language,omitempty" json:"language"`
+	Tags         []string      `yaml:"tags" json:"tags"`
+	Aliases      []string      `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	Command      string        `yaml:"command" json:"command"`
+	Files        []File        `yaml:"files,omitempty" json:"files,omitempty"`
+	Hooks        []string      `yaml:"hooks,omitempty" json:"hooks,omitempty"`
+	Secret       bool          `yaml:"secret,omitempty" json:"secret"`
+	Pinned       bool          `yaml:"pinned,omitempty" json:"pinned"`
+	Placeholders []Placeholder `yaml:"placeholders,omitempty" json:"placeholders,omitempty"`
+	CreatedAt    time.Time     `yaml:"created_at,omitempty" json:"created_at"`
+	UpdatedAt    time.Time     `yaml:"updated_at,omitempty" json:"updated_at"`
+}
+
+// Placeholder describes a {{name}} variable in a snippet command
+type Placeholder struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Default     string `yaml:"default,omitempty" json:"default,omitempty"`
+	Secret      bool   `yaml:"secret,omitempty" json:"secret,omitempty"`
+}
+
+// File is a named file bundled with a snippet, such as a Dockerfile next to a script
+type File struct {
+	Name     string `yaml:"name" json:"name"`
+	Language string `yaml:"language,omitempty" json:"language,omitempty"`
+	Content  string `yaml:"content" json:"content"`
 }
 
 // SnippetsFile represents the structure of the snippets.yaml file
 type SnippetsFile struct {
+	// Version is the file format version; see CurrentVersion
+	Version  int                `yaml:"version" json:"version"`
 	Snippets map[string]Snippet `yaml:"snippets" json:"snippets"`
+
+	// loaded holds the stored form of each snippet as read by a store that saves snippets
+	// one by one, so it can tell the caller's changes from concurrent ones; nil otherwise
+	loaded map[string]string
 }
 
 // NewSnippet creates a new snippet with the given parameters
@@ -27,7 +59,7 @@ func NewSnippet(name, description, command, language string, tags []string) Snip
 		Name:        name,
 		Description: description,
 		Language:    language,
-		Tags:        tags,
+		Tags:        NormalizeTags(tags),
 		Command:     command,
 		CreatedAt:   now,
 		UpdatedAt:   now,
@@ -39,14 +71,83 @@ func (s *Snippet) Update(description, command, language string, tags []string) {
 	if description != "" {
 		s.Description = description
 	}
-	if command != "" {
+	if command != "" && command != SecretMask {
 		s.Command = command
 	}
 	if language != "" {
 		s.Language = language
 	}
 	if tags != nil {
-		s.Tags = tags
+		s.Tags = NormalizeTags(tags)
 	}
 	s.UpdatedAt = time.Now()
 }
+
+// Masked returns a copy of the snippet with secret values replaced by SecretMask
+func (s Snippet) Masked() Snippet {
+	if s.Secret {
+		s.Command = SecretMask
+		if len(s.Files) > 0 {
+			files := make([]File, len(s.Files))
+			for i, f := range s.Files {
+				f.Content = SecretMask
+				files[i] = f
+			}
+			s.Files = files
+		}
+	}
+	if len(s.Placeholders) > 0 {
+		placeholders := make([]Placeholder, len(s.Placeholders))
+		for i, p := range s.Placeholders {
+			if p.Secret && p.Default != "" {
+				p.Default = SecretMask
+			}
+			placeholders[i] = p
+		}
+		s.Placeholders = placeholders
+	}
+	return s
+}
+
+// Placeholder returns the placeholder with the given name, or nil
+func (s *Snippet) Placeholder(name string) *Placeholder {
+	for i := range s.Placeholders {
+		if s.Placeholders[i].Name == name {
+			return &s.Placeholders[i]
+		}
+	}
+	return nil
+}
+
+// File returns the bundled file with the given name, or nil
+func (s *Snippet) File(name string) *File {
+	for i := range s.Files {
+		if s.Files[i].Name == name {
+			return &s.Files[i]
+		}
+	}
+	return nil
+}
+
+// ApplyDefaults fills in a missing language and tags, and missing file languages,
+// from what the classifier infers about the content
+func (s *Snippet) ApplyDefaults() {
+	content := s.Command
+	if content == "" && len(s.Files) > 0 {
+		content = s.Files[0].Content
+	}
+
+	result := classify.Classify(content)
+	if s.Language == "" {
+		s.Language = result.Language
+	}
+	if len(s.Tags) == 0 && len(result.Tags) > 0 {
+		s.Tags = NormalizeTags(result.Tags)
+	}
+
+	for i, f := range s.Files {
+		if f.Language == "" {
+			s.Files[i].Language = classify.DetectFileLanguage(f.Name, f.Content)
+		}
+	}
+}
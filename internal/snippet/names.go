@@ -0,0 +1,116 @@
+package snippet
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify turns free text into a snippet name
+func Slugify(text string) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(text), "-"), "-")
+	if len(slug) > 50 {
+		slug = strings.TrimRight(slug[:50], "-")
+	}
+	if slug == "" {
+		return "snippet"
+	}
+	return slug
+}
+
+// MaxNameLength is the longest allowed snippet name, including namespaces
+const MaxNameLength = 128
+
+// NamespaceSeparator separates namespaces in snippet names such as k8s/pod
+const NamespaceSeparator = "/"
+
+// segmentPattern allows name segments that are safe in URLs, file names and shell arguments
+var segmentPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ReservedNames are the API action words, as in /api/snippets/{name}/pin. They cannot end
+// a namespaced name, since a path like k8s/pin would address both a snippet and an action.
+var ReservedNames = []string{"lint", "pin", "rename"}
+
+// ValidateName checks a snippet name against the naming rules
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("snippet name is required")
+	}
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("snippet name '%s' is longer than %d characters", name, MaxNameLength)
+	}
+	for _, segment := range strings.Split(name, NamespaceSeparator) {
+		if !segmentPattern.MatchString(segment) {
+			return fmt.Errorf("invalid snippet name '%s': use letters, digits, '.', '_' and '-' separated into namespaces by '/', each part starting with a letter or digit (suggestion: '%s')", name, SlugifyName(name))
+		}
+	}
+	if endsInReserved(name) {
+		return fmt.Errorf("invalid snippet name '%s': a namespaced name cannot end in '%s' (suggestion: '%s')", name, name[strings.LastIndex(name, NamespaceSeparator)+1:], SlugifyName(name))
+	}
+	return nil
+}
+
+// endsInReserved reports whether a namespaced name ends in one of the ReservedNames
+func endsInReserved(name string) bool {
+	i := strings.LastIndex(name, NamespaceSeparator)
+	if i < 0 {
+		return false
+	}
+	for _, reserved := range ReservedNames {
+		if name[i+1:] == reserved {
+			return true
+		}
+	}
+	return false
+}
+
+// SlugifyName slugifies each namespace of a name, dropping empty ones
+func SlugifyName(name string) string {
+	var segments []string
+	for _, segment := range strings.Split(name, NamespaceSeparator) {
+		if strings.TrimSpace(segment) != "" {
+			segments = append(segments, Slugify(segment))
+		}
+	}
+	if len(segments) == 0 {
+		return Slugify(name)
+	}
+	slug := strings.Join(segments, NamespaceSeparator)
+	if endsInReserved(slug) {
+		slug += "-snippet"
+	}
+	return slug
+}
+
+// Namespace returns the namespace part of a name, or "" for top-level names
+func Namespace(name string) string {
+	if i := strings.LastIndex(name, NamespaceSeparator); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// InNamespace checks if a name is inside the namespace or any of its children.
+// An empty namespace contains every name.
+func InNamespace(name, namespace string) bool {
+	namespace = strings.Trim(namespace, NamespaceSeparator)
+	return namespace == "" || strings.HasPrefix(name, namespace+NamespaceSeparator)
+}
+
+// CheckName validates a new snippet name unless the naming rules are overridden
+func (s *Service) CheckName(name string) error {
+	if s.allowAnyName {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("snippet name is required")
+		}
+		return nil
+	}
+	return ValidateName(name)
+}
+
+// SetAllowAnyName overrides the naming rules for snippets created or renamed by this service
+func (s *Service) SetAllowAnyName(allow bool) {
+	s.allowAnyName = allow
+}
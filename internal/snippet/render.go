@@ -0,0 +1,118 @@
+package snippet
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// templatePattern matches {{ ... }} directives in a command
+	templatePattern = regexp.MustCompile(`\{\{\s*(.*?)\s*\}\}`)
+
+	// placeholderNamePattern matches names usable as {{name}} placeholders
+	placeholderNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+)
+
+// PlaceholderNames returns the {{name}} placeholders used in a command, in order of first use
+func PlaceholderNames(command string) []string {
+	var names []string
+	for _, match := range templatePattern.FindAllStringSubmatch(command, -1) {
+		name := match[1]
+		if placeholderNamePattern.MatchString(name) && !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RenderOptions controls how a snippet command is rendered
+type RenderOptions struct {
+	// Values override placeholder defaults
+	Values map[string]string
+	// Resolver resolves env/file/cmd references; nil leaves them untouched
+	Resolver *Resolver
+	// Quote, if set, is applied to every substituted value, e.g. ShellQuote for text run by a shell
+	Quote func(string) string
+}
+
+// ShellQuote quotes a value as a single shell word
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// Render fills the {{name}} placeholders in the command and resolves references.
+// Values take precedence over placeholder defaults; placeholders without either are left untouched.
+func (s *Snippet) Render(opts RenderOptions) (string, error) {
+	return s.RenderText(s.Command, opts)
+}
+
+// RenderText renders text such as a bundled file with the snippet's placeholders
+func (s *Snippet) RenderText(text string, opts RenderOptions) (string, error) {
+	var renderErr error
+	rendered := templatePattern.ReplaceAllStringFunc(text, func(token string) string {
+		directive := templatePattern.FindStringSubmatch(token)[1]
+
+		if kind, ref, ok := ParseReference(directive); ok {
+			if opts.Resolver == nil || renderErr != nil {
+				return token
+			}
+			value, err := opts.Resolver.Resolve(kind, ref)
+			if err != nil {
+				renderErr = err
+				return token
+			}
+			return opts.quote(value)
+		}
+
+		if value, ok := opts.Values[directive]; ok {
+			return opts.quote(value)
+		}
+		if p := s.Placeholder(directive); p != nil && p.Default != "" {
+			return opts.quote(p.Default)
+		}
+		return token
+	})
+
+	if renderErr != nil {
+		return "", renderErr
+	}
+	return rendered, nil
+}
+
+// quote applies the Quote option to a substituted value
+func (opts RenderOptions) quote(value string) string {
+	if opts.Quote == nil {
+		return value
+	}
+	return opts.Quote(value)
+}
+
+// ReplaceDirectives replaces every {{...}} directive in text with the result of replace,
+// which receives the trimmed directive
+func ReplaceDirectives(text string, replace func(directive string) string) string {
+	return templatePattern.ReplaceAllStringFunc(text, func(token string) string {
+		return replace(templatePattern.FindStringSubmatch(token)[1])
+	})
+}
+
+// IsPlaceholderName checks if a directive names a {{name}} placeholder
+func IsPlaceholderName(directive string) bool {
+	return placeholderNamePattern.MatchString(directive)
+}
+
+// Directive is a {{...}} directive found in text
+type Directive struct {
+	// Start and End are the byte offsets of the whole {{...}} token
+	Start, End int
+	// Text is the trimmed directive between the braces
+	Text string
+}
+
+// FindDirectives returns the {{...}} directives in text, in order
+func FindDirectives(text string) []Directive {
+	var directives []Directive
+	for _, match := range templatePattern.FindAllStringSubmatchIndex(text, -1) {
+		directives = append(directives, Directive{Start: match[0], End: match[1], Text: text[match[2]:match[3]]})
+	}
+	return directives
+}
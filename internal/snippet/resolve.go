@@ -0,0 +1,254 @@
+package snippet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/config"
+)
+
+// Reference kinds that can be resolved at render time
+const (
+	RefEnv  = "env"
+	RefFile = "file"
+	RefCmd  = "cmd"
+)
+
+// Resolver resolves {{env:NAME}}, {{file:PATH}} and {{cmd:COMMAND}} references
+// that are allowed by the resolve section of config.yaml
+type Resolver struct {
+	allow config.ResolveConfig
+}
+
+// NewResolver creates a resolver limited to the given allowlist
+func NewResolver(allow config.ResolveConfig) *Resolver {
+	return &Resolver{allow: allow}
+}
+
+// Resolver returns a resolver using the configured allowlist
+func (s *Service) Resolver() *Resolver {
+	return NewResolver(s.config.Resolve)
+}
+
+// ParseReference splits a template directive such as "env:HOME" into its kind and value
+func ParseReference(directive string) (kind, ref string, ok bool) {
+	kind, ref, ok = strings.Cut(directive, ":")
+	if !ok {
+		return "", "", false
+	}
+	switch kind {
+	case RefEnv, RefFile, RefCmd:
+		return kind, strings.TrimSpace(ref), true
+	}
+	return "", "", false
+}
+
+// Resolve returns the value of a reference
+func (r *Resolver) Resolve(kind, ref string) (string, error) {
+	switch kind {
+	case RefEnv:
+		if !matchAny(r.allow.Env, ref) {
+			return "", notAllowed(kind, ref)
+		}
+		value, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", ref)
+		}
+		return value, nil
+
+	case RefFile:
+		path, err := cleanPath(ref)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", ref, err)
+		}
+		if !matchAnyPath(r.allow.File, path) {
+			return "", notAllowed(kind, ref)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", ref, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+
+	case RefCmd:
+		argv, err := splitCommand(ref)
+		if err != nil {
+			return "", fmt.Errorf("{{%s:%s}}: %w", kind, ref, err)
+		}
+		if !matchAnyCommand(r.allow.Cmd, argv) {
+			return "", notAllowed(kind, ref)
+		}
+		// Run without a shell, so the allowlist covers exactly what is executed
+		output, err := exec.Command(argv[0], argv[1:]...).Output()
+		if err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+				return "", fmt.Errorf("command '%s' failed: %w: %s", ref, err, strings.TrimSpace(string(exitErr.Stderr)))
+			}
+			return "", fmt.Errorf("command '%s' failed: %w", ref, err)
+		}
+		return strings.TrimRight(string(output), "\r\n"), nil
+	}
+
+	return "", fmt.Errorf("unknown reference kind '%s'", kind)
+}
+
+// notAllowed reports a reference missing from the allowlist
+func notAllowed(kind, ref string) error {
+	return fmt.Errorf("{{%s:%s}} is not allowed; add it to resolve.%s in config.yaml", kind, ref, kind)
+}
+
+// matchAny checks if the value matches any of the patterns
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern matches a value against a pattern where * matches any sequence of characters
+func matchPattern(pattern, value string) bool {
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	matched, err := regexp.MatchString(expr, value)
+	return err == nil && matched
+}
+
+// shellMetacharacters may not appear in a {{cmd:...}} reference, which is run without a shell
+const shellMetacharacters = ";|&$`<>()\\\n\r"
+
+// splitCommand splits a {{cmd:...}} reference into argv words. Words may be quoted with
+// single or double quotes; shell syntax such as pipes, substitutions and redirections is rejected.
+func splitCommand(ref string) ([]string, error) {
+	if i := strings.IndexAny(ref, shellMetacharacters); i >= 0 {
+		return nil, fmt.Errorf("shell syntax %q is not supported in command references", ref[i])
+	}
+
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	for _, c := range ref {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote, inWord = c, true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in command reference")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty command reference")
+	}
+	return words, nil
+}
+
+// matchAnyCommand checks if argv matches any command pattern
+func matchAnyCommand(patterns []string, argv []string) bool {
+	for _, pattern := range patterns {
+		if matchCommand(pattern, argv) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCommand matches argv against a command pattern word by word.
+// A * matches any characters within one word, so "pass show *" allows exactly one argument.
+func matchCommand(pattern string, argv []string) bool {
+	words, err := splitCommand(pattern)
+	if err != nil || len(words) != len(argv) {
+		return false
+	}
+	for i, word := range words {
+		if !matchPattern(word, argv[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAnyPath checks if a cleaned path matches any path pattern
+func matchAnyPath(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if matchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath matches a cleaned path against a path pattern with filepath.Match,
+// so * never crosses a directory boundary
+func matchPath(pattern, path string) bool {
+	pattern, err := cleanPattern(pattern)
+	if err != nil {
+		return false
+	}
+	matched, err := filepath.Match(pattern, path)
+	return err == nil && matched
+}
+
+// cleanPath expands ~ and returns the absolute path with .. and symlinks resolved
+func cleanPath(path string) (string, error) {
+	abs, err := filepath.Abs(expandHome(path))
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// cleanPattern normalizes a path pattern like cleanPath, resolving symlinks
+// in the directories before the first wildcard
+func cleanPattern(pattern string) (string, error) {
+	abs, err := filepath.Abs(expandHome(pattern))
+	if err != nil {
+		return "", err
+	}
+
+	prefix, rest := abs, ""
+	for strings.ContainsAny(prefix, "*?[") {
+		rest = filepath.Join(filepath.Base(prefix), rest)
+		prefix = filepath.Dir(prefix)
+	}
+	if resolved, err := filepath.EvalSymlinks(prefix); err == nil {
+		prefix = resolved
+	}
+	return filepath.Join(prefix, rest), nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
@@ -0,0 +1,205 @@
+package snippet
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/atobaum/snippet-manager/internal/config"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"HOME", "HOME", true},
+		{"HOME", "HOMEDIR", false},
+		{"AWS_*", "AWS_PROFILE", true},
+		{"AWS_*", "AWS_", true},
+		{"AWS_*", "MY_AWS_PROFILE", false},
+		{"*_TOKEN", "GITHUB_TOKEN", true},
+		{"*", "", true},
+		{"a.b", "axb", false},
+		{"[a]", "a", false},
+		{"", "", true},
+		{"", "HOME", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.value); got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    []string
+		wantErr string
+	}{
+		{ref: "pass show db", want: []string{"pass", "show", "db"}},
+		{ref: "  git   rev-parse\tHEAD ", want: []string{"git", "rev-parse", "HEAD"}},
+		{ref: `echo 'hello world'`, want: []string{"echo", "hello world"}},
+		{ref: `echo "it's" ''`, want: []string{"echo", "it's", ""}},
+		{ref: `echo a"b c"d`, want: []string{"echo", "ab cd"}},
+		{ref: "echo 'unterminated", wantErr: "unterminated quote"},
+		{ref: "   ", wantErr: "empty command reference"},
+		{ref: "cat file | grep x", wantErr: "shell syntax"},
+		{ref: "echo $HOME", wantErr: "shell syntax"},
+		{ref: "echo `id`", wantErr: "shell syntax"},
+		{ref: "ls > out", wantErr: "shell syntax"},
+		{ref: "true; rm -rf /", wantErr: "shell syntax"},
+		{ref: "echo 'a;b'", wantErr: "shell syntax"},
+	}
+
+	for _, tt := range tests {
+		got, err := splitCommand(tt.ref)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("splitCommand(%q) error = %v, want error containing %q", tt.ref, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitCommand(%q) unexpected error: %v", tt.ref, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCommand(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestMatchCommand(t *testing.T) {
+	tests := []struct {
+		pattern string
+		argv    []string
+		want    bool
+	}{
+		{"pass show db", []string{"pass", "show", "db"}, true},
+		{"pass show *", []string{"pass", "show", "db"}, true},
+		{"pass show *", []string{"pass", "show"}, false},
+		{"pass show *", []string{"pass", "show", "db", "--clip"}, false},
+		{"pass show work/*", []string{"pass", "show", "work/db"}, true},
+		{"pass show work/*", []string{"pass", "show", "home/db"}, false},
+		{"git config 'user.*'", []string{"git", "config", "user.email"}, true},
+		{"echo 'a b'", []string{"echo", "a b"}, true},
+		{"echo 'a b'", []string{"echo", "a", "b"}, false},
+		{"cat x | sh", []string{"cat", "x", "|", "sh"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := matchCommand(tt.pattern, tt.argv); got != tt.want {
+			t.Errorf("matchCommand(%q, %q) = %v, want %v", tt.pattern, tt.argv, got, tt.want)
+		}
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets := filepath.Join(dir, "secrets")
+	if err := os.Mkdir(secrets, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(secrets, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"exact path", filepath.Join(secrets, "token"), filepath.Join(secrets, "token"), true},
+		{"wildcard in file name", filepath.Join(secrets, "*.key"), filepath.Join(secrets, "db.key"), true},
+		{"wildcard does not cross directories", filepath.Join(secrets, "*"), filepath.Join(secrets, "nested", "token"), false},
+		{"other directory", filepath.Join(secrets, "*"), filepath.Join(dir, "token"), false},
+		{"dot-dot in pattern", filepath.Join(secrets, "nested", "..", "*"), filepath.Join(secrets, "token"), true},
+		{"symlinked pattern directory", filepath.Join(link, "*"), filepath.Join(secrets, "token"), true},
+		{"malformed pattern", filepath.Join(secrets, "["), filepath.Join(secrets, "["), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchPath(tt.pattern, tt.path); got != tt.want {
+				t.Errorf("matchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := filepath.Join(dir, "token")
+	if err := os.WriteFile(token, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "other"), []byte("other"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(token, filepath.Join(dir, "token-link")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SNI_TEST_PROFILE", "dev")
+	t.Setenv("SNI_TEST_SECRET", "hidden")
+
+	resolver := NewResolver(config.ResolveConfig{
+		Env:  []string{"SNI_TEST_PROFILE", "SNI_TEST_MISSING"},
+		File: []string{token, filepath.Join(dir, "public", "*")},
+		Cmd:  []string{"echo *", "false"},
+	})
+
+	tests := []struct {
+		name    string
+		kind    string
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{name: "allowed env", kind: RefEnv, ref: "SNI_TEST_PROFILE", want: "dev"},
+		{name: "env not allowed", kind: RefEnv, ref: "SNI_TEST_SECRET", wantErr: "is not allowed"},
+		{name: "env not set", kind: RefEnv, ref: "SNI_TEST_MISSING", wantErr: "is not set"},
+		{name: "allowed file", kind: RefFile, ref: token, want: "s3cret"},
+		{name: "file through dot-dot", kind: RefFile, ref: dir + "/public/../token", want: "s3cret"},
+		{name: "dot-dot out of an allowed directory", kind: RefFile, ref: dir + "/public/../other", wantErr: "is not allowed"},
+		{name: "file through symlink", kind: RefFile, ref: filepath.Join(dir, "token-link"), want: "s3cret"},
+		{name: "file not allowed", kind: RefFile, ref: filepath.Join(dir, "other"), wantErr: "is not allowed"},
+		{name: "allowed command", kind: RefCmd, ref: "echo hello", want: "hello"},
+		{name: "command with too many words", kind: RefCmd, ref: "echo hello world", wantErr: "is not allowed"},
+		{name: "command not allowed", kind: RefCmd, ref: "date", wantErr: "is not allowed"},
+		{name: "shell syntax", kind: RefCmd, ref: "echo hi; id", wantErr: "shell syntax"},
+		{name: "failing command", kind: RefCmd, ref: "false", wantErr: "failed"},
+		{name: "unknown kind", kind: "url", ref: "https://example.com", wantErr: "unknown reference kind"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.Resolve(tt.kind, tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Resolve(%s, %q) error = %v, want error containing %q", tt.kind, tt.ref, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%s, %q) unexpected error: %v", tt.kind, tt.ref, err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%s, %q) = %q, want %q", tt.kind, tt.ref, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package snippet
+
+import (
+	"fmt"
+
+	"github.com/atobaum/snippet-manager/internal/secret"
+)
+
+// keys returns the keyring, loading it on first use
+func (s *Service) keys() (*secret.Keyring, error) {
+	if s.keyring == nil {
+		keyring, err := secret.NewKeyring(s.config.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		s.keyring = keyring
+	}
+	return s.keyring, nil
+}
+
+// sealSecrets encrypts secret values that are still stored in plain text
+func (s *Service) sealSecrets(snippetsFile *SnippetsFile) error {
+	for name, snippet := range snippetsFile.Snippets {
+		changed, copied, filesCopied := false, false, false
+
+		if snippet.Secret && snippet.Command != "" && !secret.IsEncrypted(snippet.Command) {
+			keyring, err := s.keys()
+			if err != nil {
+				return err
+			}
+			if snippet.Command, err = keyring.Encrypt(snippet.Command); err != nil {
+				return fmt.Errorf("failed to encrypt snippet '%s': %w", name, err)
+			}
+			changed = true
+		}
+
+		for i, f := range snippet.Files {
+			if !snippet.Secret || f.Content == "" || secret.IsEncrypted(f.Content) {
+				continue
+			}
+			if !filesCopied {
+				snippet.Files = append([]File(nil), snippet.Files...)
+				filesCopied = true
+			}
+			keyring, err := s.keys()
+			if err != nil {
+				return err
+			}
+			if snippet.Files[i].Content, err = keyring.Encrypt(f.Content); err != nil {
+				return fmt.Errorf("failed to encrypt file '%s' of snippet '%s': %w", f.Name, name, err)
+			}
+			changed = true
+		}
+
+		for i, p := range snippet.Placeholders {
+			if !p.Secret || p.Default == "" || secret.IsEncrypted(p.Default) {
+				continue
+			}
+			if !copied {
+				snippet.Placeholders = append([]Placeholder(nil), snippet.Placeholders...)
+				copied = true
+			}
+			keyring, err := s.keys()
+			if err != nil {
+				return err
+			}
+			if snippet.Placeholders[i].Default, err = keyring.Encrypt(p.Default); err != nil {
+				return fmt.Errorf("failed to encrypt placeholder '%s' of snippet '%s': %w", p.Name, name, err)
+			}
+			changed = true
+		}
+
+		if changed {
+			snippetsFile.Snippets[name] = snippet
+		}
+	}
+	return nil
+}
+
+// RevealSnippet retrieves a snippet by name with secret values decrypted.
+// Only use the result for rendering; never store or export it.
+func (s *Service) RevealSnippet(name string) (*Snippet, error) {
+	snippetsFile, err := s.LoadSnippets()
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, exists := snippetsFile.Resolve(name)
+	if !exists {
+		return nil, fmt.Errorf("snippet '%s' %w", name, ErrNotFound)
+	}
+	snippet := snippetsFile.Snippets[resolved]
+	snippet.Name = resolved
+
+	if err := s.reveal(&snippet); err != nil {
+		return nil, err
+	}
+	return &snippet, nil
+}
+
+// reveal decrypts the secret values of a snippet in place
+func (s *Service) reveal(snippet *Snippet) error {
+	if secret.IsEncrypted(snippet.Command) {
+		keyring, err := s.keys()
+		if err != nil {
+			return err
+		}
+		if snippet.Command, err = keyring.Decrypt(snippet.Command); err != nil {
+			return fmt.Errorf("snippet '%s': %w", snippet.Name, err)
+		}
+	}
+
+	if len(snippet.Files) > 0 {
+		files := make([]File, len(snippet.Files))
+		for i, f := range snippet.Files {
+			if secret.IsEncrypted(f.Content) {
+				keyring, err := s.keys()
+				if err != nil {
+					return err
+				}
+				if f.Content, err = keyring.Decrypt(f.Content); err != nil {
+					return fmt.Errorf("file '%s' of snippet '%s': %w", f.Name, snippet.Name, err)
+				}
+			}
+			files[i] = f
+		}
+		snippet.Files = files
+	}
+
+	placeholders := make([]Placeholder, len(snippet.Placeholders))
+	for i, p := range snippet.Placeholders {
+		if secret.IsEncrypted(p.Default) {
+			keyring, err := s.keys()
+			if err != nil {
+				return err
+			}
+			if p.Default, err = keyring.Decrypt(p.Default); err != nil {
+				return fmt.Errorf("placeholder '%s' of snippet '%s': %w", p.Name, snippet.Name, err)
+			}
+		}
+		placeholders[i] = p
+	}
+	snippet.Placeholders = placeholders
+
+	return nil
+}
+
+// SetSecret marks a snippet as secret or not, encrypting or decrypting its command and files
+func (s *Service) SetSecret(name string, enabled bool) error {
+	return s.ModifySnippet(name, func(snippet *Snippet) error {
+		return s.setSecret(snippet, enabled)
+	})
+}
+
+// setSecret marks a snippet as secret, or decrypts its command and files to store them in plain text
+func (s *Service) setSecret(snippet *Snippet, enabled bool) error {
+	if enabled {
+		snippet.Secret = true
+		return nil
+	}
+
+	// Placeholder defaults stay secret on their own
+	placeholders := snippet.Placeholders
+	if err := s.reveal(snippet); err != nil {
+		return err
+	}
+	snippet.Placeholders = placeholders
+	snippet.Secret = false
+	return nil
+}
+
+// SetSecretPlaceholder stores a secret default value for a placeholder, creating it if needed
+func (s *Service) SetSecretPlaceholder(name, placeholder, value string) error {
+	if !placeholderNamePattern.MatchString(placeholder) {
+		return fmt.Errorf("invalid placeholder name '%s'", placeholder)
+	}
+
+	return s.ModifySnippet(name, func(snippet *Snippet) error {
+		placeholders := append([]Placeholder(nil), snippet.Placeholders...)
+		snippet.Placeholders = placeholders
+
+		if p := snippet.Placeholder(placeholder); p != nil {
+			p.Default = value
+			p.Secret = true
+			return nil
+		}
+		snippet.Placeholders = append(snippet.Placeholders, Placeholder{
+			Name:    placeholder,
+			Default: value,
+			Secret:  true,
+		})
+		return nil
+	})
+}
@@ -1,17 +1,32 @@
 package snippet
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/atobaum/snippet-manager/internal/config"
-	"gopkg.in/yaml.v3"
+	"github.com/atobaum/snippet-manager/internal/secret"
+)
+
+var (
+	// ErrNotFound is wrapped by the errors returned for snippets that do not exist
+	ErrNotFound = errors.New("not found")
+	// ErrIncluded is wrapped by the error returned when deleting a snippet other snippets include
+	ErrIncluded = errors.New("included by")
 )
 
 // Service handles snippet operations
 type Service struct {
-	config *config.Config
+	config       *config.Config
+	store        Store
+	events       *eventLog
+	keyring      *secret.Keyring
+	allowAnyName bool
+	tagSynonyms  map[string]string
 }
 
 // NewService creates a new snippet service
@@ -26,162 +41,313 @@ func NewService() (*Service, error) {
 		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	store, err := NewStore(cfg, cfg.Storage.Backend)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Service{
-		config: cfg,
+		config:       cfg,
+		store:        store,
+		allowAnyName: cfg.Naming.AllowAny,
+		tagSynonyms:  tagSynonyms(cfg.Tags.Synonyms),
 	}, nil
 }
 
-// LoadSnippets loads all snippets from the YAML file
+// LoadSnippets loads all snippets from the configured store
 func (s *Service) LoadSnippets() (*SnippetsFile, error) {
-	// If file doesn't exist, return empty snippets
-	if _, err := os.Stat(s.config.SnippetFile); os.IsNotExist(err) {
-		return &SnippetsFile{
-			Snippets: make(map[string]Snippet),
-		}, nil
+	return s.store.Load()
+}
+
+// SaveSnippets saves all snippets to the configured store, backing up the previous version first
+func (s *Service) SaveSnippets(snippetsFile *SnippetsFile) error {
+	if err := s.sealSecrets(snippetsFile); err != nil {
+		return err
+	}
+	if err := s.backupSnippets(); err != nil {
+		return err
+	}
+	return s.store.Save(snippetsFile)
+}
+
+// CreateSnippet creates a new snippet
+func (s *Service) CreateSnippet(name, description, command, language string, tags []string) error {
+	return s.AddSnippet(NewSnippet(name, description, command, language, tags))
+}
+
+// AddSnippet stores a fully populated new snippet
+func (s *Service) AddSnippet(snippet Snippet) error {
+	if err := s.CheckName(snippet.Name); err != nil {
+		return err
+	}
+	if err := ValidateFiles(snippet.Files); err != nil {
+		return err
 	}
 
-	data, err := os.ReadFile(s.config.SnippetFile)
+	snippetsFile, err := s.LoadSnippets()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read snippets file: %w", err)
+		return err
 	}
 
-	var snippetsFile SnippetsFile
-	if err := yaml.Unmarshal(data, &snippetsFile); err != nil {
-		return nil, fmt.Errorf("failed to parse snippets file: %w", err)
+	if err := snippetsFile.checkAvailable(snippet.Name); err != nil {
+		return err
 	}
-
-	// Initialize map if nil
-	if snippetsFile.Snippets == nil {
-		snippetsFile.Snippets = make(map[string]Snippet)
+	if err := s.checkAliases(snippetsFile, snippet.Name, snippet.Aliases); err != nil {
+		return err
 	}
 
-	return &snippetsFile, nil
+	snippet.Tags = s.NormalizeTags(snippet.Tags)
+	snippetsFile.Snippets[snippet.Name] = snippet
+
+	return s.SaveSnippets(snippetsFile)
 }
 
-// SaveSnippets saves all snippets to the YAML file
-func (s *Service) SaveSnippets(snippetsFile *SnippetsFile) error {
-	data, err := yaml.Marshal(snippetsFile)
+// UniqueName returns name if it is free, or name with the first unused numeric suffix
+func (s *Service) UniqueName(name string) (string, error) {
+	snippetsFile, err := s.LoadSnippets()
 	if err != nil {
-		return fmt.Errorf("failed to marshal snippets: %w", err)
+		return "", err
+	}
+	if _, exists := snippetsFile.Resolve(name); !exists {
+		return name, nil
+	}
+	return uniqueName(snippetsFile, name), nil
+}
+
+// GetSnippet retrieves a snippet by name with secret values masked
+func (s *Service) GetSnippet(name string) (*Snippet, error) {
+	snippetsFile, err := s.LoadSnippets()
+	if err != nil {
+		return nil, err
 	}
 
-	if err := os.WriteFile(s.config.SnippetFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write snippets file: %w", err)
+	resolved, exists := snippetsFile.Resolve(name)
+	if !exists {
+		return nil, fmt.Errorf("snippet '%s' %w", name, ErrNotFound)
 	}
+	name = resolved
+	snippet := snippetsFile.Snippets[name]
 
-	return nil
+	snippet = snippet.Masked()
+	return &snippet, nil
 }
 
-// CreateSnippet creates a new snippet
-func (s *Service) CreateSnippet(name, description, command, language string, tags []string) error {
+// UpdateSnippet updates an existing snippet
+func (s *Service) UpdateSnippet(name, description, command, language string, tags []string) error {
 	snippetsFile, err := s.LoadSnippets()
 	if err != nil {
 		return err
 	}
 
-	if _, exists := snippetsFile.Snippets[name]; exists {
-		return fmt.Errorf("snippet '%s' already exists", name)
+	resolved, exists := snippetsFile.Resolve(name)
+	if !exists {
+		return fmt.Errorf("snippet '%s' %w", name, ErrNotFound)
 	}
+	name = resolved
+	snippet := snippetsFile.Snippets[name]
 
-	snippet := NewSnippet(name, description, command, language, tags)
+	snippet.Update(description, command, language, s.NormalizeTags(tags))
 	snippetsFile.Snippets[name] = snippet
 
 	return s.SaveSnippets(snippetsFile)
 }
 
-// GetSnippet retrieves a snippet by name
-func (s *Service) GetSnippet(name string) (*Snippet, error) {
+// SnippetChanges lists the fields replaced by ApplyChanges. Empty strings and nil
+// fields keep the current value, as with UpdateSnippet.
+type SnippetChanges struct {
+	Description string
+	Command     string
+	Language    string
+	Tags        []string
+	Aliases     []string
+	Files       []File
+	Hooks       []string
+	Secret      *bool
+}
+
+// ApplyChanges validates and applies all changes to a snippet in one save,
+// so a change that is rejected leaves the snippet untouched
+func (s *Service) ApplyChanges(name string, changes SnippetChanges) error {
+	if changes.Files != nil {
+		if err := ValidateFiles(changes.Files); err != nil {
+			return err
+		}
+	}
+
+	return s.modifyInFile(name, func(snippetsFile *SnippetsFile, snippet *Snippet) error {
+		if changes.Aliases != nil {
+			if err := s.checkAliases(snippetsFile, snippet.Name, changes.Aliases); err != nil {
+				return err
+			}
+			snippet.Aliases = changes.Aliases
+		}
+		snippet.Update(changes.Description, changes.Command, changes.Language, changes.Tags)
+		if changes.Files != nil {
+			replaceFiles(snippet, changes.Files)
+		}
+		if changes.Hooks != nil {
+			snippet.Hooks = changes.Hooks
+		}
+		if changes.Secret != nil {
+			return s.setSecret(snippet, *changes.Secret)
+		}
+		return nil
+	})
+}
+
+// ModifySnippet applies changes to an existing snippet and saves it
+func (s *Service) ModifySnippet(name string, modify func(*Snippet) error) error {
+	return s.modifyInFile(name, func(_ *SnippetsFile, snippet *Snippet) error {
+		return modify(snippet)
+	})
+}
+
+// modifyInFile is ModifySnippet for changes that are checked against the other snippets
+func (s *Service) modifyInFile(name string, modify func(*SnippetsFile, *Snippet) error) error {
 	snippetsFile, err := s.LoadSnippets()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	snippet, exists := snippetsFile.Snippets[name]
+	resolved, exists := snippetsFile.Resolve(name)
 	if !exists {
-		return nil, fmt.Errorf("snippet '%s' not found", name)
+		return fmt.Errorf("snippet '%s' %w", name, ErrNotFound)
 	}
+	name = resolved
+	snippet := snippetsFile.Snippets[name]
 
-	return &snippet, nil
+	if err := modify(snippetsFile, &snippet); err != nil {
+		return err
+	}
+	snippet.Tags = s.NormalizeTags(snippet.Tags)
+	snippet.UpdatedAt = time.Now()
+	snippetsFile.Snippets[name] = snippet
+
+	return s.SaveSnippets(snippetsFile)
 }
 
-// UpdateSnippet updates an existing snippet
-func (s *Service) UpdateSnippet(name, description, command, language string, tags []string) error {
+// RenameSnippet changes the name of a snippet, keeping its content and creation time,
+// and updates the {{> name}} includes that refer to it
+func (s *Service) RenameSnippet(oldName, newName string) error {
+	if err := s.CheckName(newName); err != nil {
+		return err
+	}
+
 	snippetsFile, err := s.LoadSnippets()
 	if err != nil {
 		return err
 	}
 
-	snippet, exists := snippetsFile.Snippets[name]
+	snippet, exists := snippetsFile.Snippets[oldName]
 	if !exists {
-		return fmt.Errorf("snippet '%s' not found", name)
+		return notFoundOrAlias(snippetsFile, oldName)
+	}
+	if err := snippetsFile.checkAvailable(newName); err != nil {
+		return err
 	}
 
-	snippet.Update(description, command, language, tags)
-	snippetsFile.Snippets[name] = snippet
+	snippet.Name = newName
+	snippet.UpdatedAt = time.Now()
+	delete(snippetsFile.Snippets, oldName)
+	snippetsFile.Snippets[newName] = snippet
+	if err := s.rewriteIncludes(snippetsFile, oldName, newName); err != nil {
+		return err
+	}
 
 	return s.SaveSnippets(snippetsFile)
 }
 
-// DeleteSnippet removes a snippet
-func (s *Service) DeleteSnippet(name string) error {
+// DeleteSnippet removes a snippet. A snippet included by other snippets is only removed with force.
+func (s *Service) DeleteSnippet(name string, force bool) error {
 	snippetsFile, err := s.LoadSnippets()
 	if err != nil {
 		return err
 	}
 
 	if _, exists := snippetsFile.Snippets[name]; !exists {
-		return fmt.Errorf("snippet '%s' not found", name)
+		return notFoundOrAlias(snippetsFile, name)
+	}
+	if !force {
+		graph, unreadable := s.includeGraph(snippetsFile)
+		if dependents := Dependents(graph, name); len(dependents) > 0 {
+			return fmt.Errorf("snippet '%s' is %w %s", name, ErrIncluded, strings.Join(dependents, ", "))
+		}
+		// Secret snippets that cannot be decrypted might include it as well
+		var unchecked []string
+		for _, other := range unreadable {
+			if other != name {
+				unchecked = append(unchecked, other)
+			}
+		}
+		if len(unchecked) > 0 {
+			return fmt.Errorf("snippet '%s' may be %w secret snippet(s) %s, which could not be decrypted", name, ErrIncluded, strings.Join(unchecked, ", "))
+		}
 	}
 
 	delete(snippetsFile.Snippets, name)
 	return s.SaveSnippets(snippetsFile)
 }
 
-// ListSnippets returns all snippets
+// ListSnippets returns all snippets sorted by name with secret values masked
 func (s *Service) ListSnippets() ([]Snippet, error) {
-	snippetsFile, err := s.LoadSnippets()
+	return s.FilterSnippets(Filter{})
+}
+
+// FilterSnippets returns the snippets matching the filter with secret values masked,
+// pinned snippets first
+func (s *Service) FilterSnippets(filter Filter) ([]Snippet, error) {
+	snippets, err := s.SealedSnippets(filter)
 	if err != nil {
 		return nil, err
 	}
 
-	snippets := make([]Snippet, 0, len(snippetsFile.Snippets))
-	for name, snippet := range snippetsFile.Snippets {
-		snippet.Name = name // Ensure name is set
-		snippets = append(snippets, snippet)
+	for i := range snippets {
+		snippets[i] = snippets[i].Masked()
 	}
 
+	// Pinned snippets come first, each group sorted by name
+	sort.SliceStable(snippets, func(i, j int) bool {
+		return snippets[i].Pinned && !snippets[j].Pinned
+	})
+
 	return snippets, nil
 }
 
-// SearchSnippets searches snippets by keyword
-func (s *Service) SearchSnippets(keyword string) ([]Snippet, error) {
-	snippets, err := s.ListSnippets()
+// SetPinned pins a snippet to the top of lists or unpins it
+func (s *Service) SetPinned(name string, pinned bool) error {
+	return s.ModifySnippet(name, func(snippet *Snippet) error {
+		snippet.Pinned = pinned
+		return nil
+	})
+}
+
+// SealedSnippets returns the snippets matching the filter as stored, with secret values still encrypted
+func (s *Service) SealedSnippets(filter Filter) ([]Snippet, error) {
+	if qs, ok := s.queries(); ok {
+		return qs.Query(filter)
+	}
+
+	snippetsFile, err := s.LoadSnippets()
 	if err != nil {
 		return nil, err
 	}
 
-	keyword = strings.ToLower(keyword)
-	var results []Snippet
-
-	for _, snippet := range snippets {
-		// Search in name, description, tags, and command
-		if strings.Contains(strings.ToLower(snippet.Name), keyword) ||
-			strings.Contains(strings.ToLower(snippet.Description), keyword) ||
-			strings.Contains(strings.ToLower(snippet.Command), keyword) ||
-			s.containsTag(snippet.Tags, keyword) {
-			results = append(results, snippet)
+	snippets := make([]Snippet, 0, len(snippetsFile.Snippets))
+	for name, snippet := range snippetsFile.Snippets {
+		snippet.Name = name // Ensure name is set
+		if filter.Match(snippet) {
+			snippets = append(snippets, snippet)
 		}
 	}
 
-	return results, nil
+	sort.Slice(snippets, func(i, j int) bool {
+		return snippets[i].Name < snippets[j].Name
+	})
+
+	return snippets, nil
 }
 
-// containsTag checks if any tag contains the keyword
-func (s *Service) containsTag(tags []string, keyword string) bool {
-	for _, tag := range tags {
-		if strings.Contains(strings.ToLower(tag), keyword) {
-			return true
-		}
-	}
-	return false
+// SearchSnippets searches snippets by keyword in name, description, tags, and command
+func (s *Service) SearchSnippets(keyword string) ([]Snippet, error) {
+	return s.FilterSnippets(Filter{Keyword: keyword})
 }
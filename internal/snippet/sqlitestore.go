@@ -0,0 +1,478 @@
+package snippet
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+	_ "modernc.org/sqlite" // pure-Go driver, no cgo
+)
+
+// sqliteSchema creates the snippets database. Each row keeps the whole snippet as YAML so nothing
+// is lost, next to the columns used for filtering and a trigram full-text index for keyword search.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS snippets (
+	id       INTEGER PRIMARY KEY,
+	name     TEXT NOT NULL UNIQUE,
+	language TEXT NOT NULL DEFAULT '',
+	pinned   INTEGER NOT NULL DEFAULT 0,
+	data     TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS snippets_language ON snippets(language COLLATE NOCASE);
+CREATE TABLE IF NOT EXISTS snippet_tags (
+	snippet_id INTEGER NOT NULL REFERENCES snippets(id) ON DELETE CASCADE,
+	tag        TEXT NOT NULL,
+	PRIMARY KEY (snippet_id, tag)
+);
+CREATE INDEX IF NOT EXISTS snippet_tags_tag ON snippet_tags(tag COLLATE NOCASE);
+CREATE VIRTUAL TABLE IF NOT EXISTS snippets_fts USING fts5(
+	name, description, command, tags, aliases,
+	tokenize = 'trigram'
+);
+`
+
+// sqliteStore keeps snippets in a SQLite database. Writes run in a single transaction and the
+// database is opened in WAL mode, so readers such as a running server never see a partial save.
+type sqliteStore struct {
+	path string
+
+	mu sync.Mutex
+	db *sql.DB
+
+	// writer runs every write transaction. Its PRAGMA data_version changes only when another
+	// connection commits, so a watching server can tell saves by other processes from its own.
+	writeMu     sync.Mutex
+	writer      *sql.Conn
+	dataVersion int64
+}
+
+// Load reads every snippet from the database
+func (st *sqliteStore) Load() (*SnippetsFile, error) {
+	snippetsFile := &SnippetsFile{
+		Version:  CurrentVersion,
+		Snippets: make(map[string]Snippet),
+		loaded:   make(map[string]string),
+	}
+
+	db, err := st.open(false)
+	if err != nil || db == nil {
+		return snippetsFile, err
+	}
+
+	rows, err := db.Query(`SELECT name, data FROM snippets`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippets database: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, data string
+		if err := rows.Scan(&name, &data); err != nil {
+			return nil, fmt.Errorf("failed to read snippets database: %w", err)
+		}
+		snippet, err := parseSnippetRow(name, data)
+		if err != nil {
+			return nil, err
+		}
+		snippetsFile.Snippets[name] = snippet
+		snippetsFile.loaded[name] = data
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read snippets database: %w", err)
+	}
+
+	return snippetsFile, nil
+}
+
+// Save writes the snippets in one transaction, rewriting only the rows that changed.
+// A collection read by Load only writes the snippets the caller added, changed or removed,
+// so snippets saved by another process in the meantime are kept. If one of the caller's
+// snippets was itself changed in the meantime, the save fails. Any other collection,
+// such as a restored backup, replaces the stored snippets.
+func (st *sqliteStore) Save(snippetsFile *SnippetsFile) error {
+	if _, err := st.open(true); err != nil {
+		return err
+	}
+
+	st.writeMu.Lock()
+	defer st.writeMu.Unlock()
+	tx, err := st.writer.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	existing, err := storedRows(tx)
+	if err != nil {
+		return err
+	}
+
+	loaded := snippetsFile.loaded
+	replace := loaded == nil
+	// checkUnchanged fails if the stored snippet is no longer the one the caller loaded
+	checkUnchanged := func(name string) error {
+		row, stored := existing[name]
+		data, known := loaded[name]
+		if stored != known || row.data != data {
+			return fmt.Errorf("snippet '%s' was changed by another process; reload and try again", name)
+		}
+		return nil
+	}
+
+	for name, row := range existing {
+		if _, ok := snippetsFile.Snippets[name]; ok {
+			continue
+		}
+		if !replace {
+			if _, known := loaded[name]; !known {
+				// Saved by another process after the caller loaded the snippets
+				continue
+			}
+			if err := checkUnchanged(name); err != nil {
+				return err
+			}
+		}
+		if err := deleteSnippetRow(tx, row.id); err != nil {
+			return err
+		}
+	}
+
+	saved := make(map[string]string, len(snippetsFile.Snippets))
+	for name, snippet := range snippetsFile.Snippets {
+		data, err := yaml.Marshal(snippet)
+		if err != nil {
+			return fmt.Errorf("failed to marshal snippet '%s': %w", name, err)
+		}
+		saved[name] = string(data)
+
+		row, ok := existing[name]
+		if replace {
+			if ok && row.data == string(data) {
+				continue
+			}
+		} else {
+			if previous, known := loaded[name]; known && previous == string(data) {
+				// Not changed by the caller
+				continue
+			}
+			if err := checkUnchanged(name); err != nil {
+				return err
+			}
+		}
+		if ok {
+			if err := deleteSnippetRow(tx, row.id); err != nil {
+				return err
+			}
+		}
+		if err := insertSnippetRow(tx, name, snippet, string(data)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`INSERT INTO meta (key, value) VALUES ('version', ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`, strconv.Itoa(CurrentVersion)); err != nil {
+		return fmt.Errorf("failed to write snippets database version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to write snippets database: %w", err)
+	}
+	snippetsFile.Version = CurrentVersion
+	snippetsFile.loaded = saved
+	return nil
+}
+
+// Snapshot returns the snippets in snippets.yaml format
+func (st *sqliteStore) Snapshot() ([]byte, error) {
+	if _, err := os.Stat(st.path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	snippetsFile, err := st.Load()
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(snippetsFile)
+}
+
+// Query selects the snippets matching the filter in SQL. Keywords of three or more characters
+// use the full-text index; shorter ones fall back to LIKE over the same columns.
+func (st *sqliteStore) Query(filter Filter) ([]Snippet, error) {
+	db, err := st.open(false)
+	if err != nil || db == nil {
+		return []Snippet{}, err
+	}
+
+	var where []string
+	var args []interface{}
+
+	if namespace := strings.Trim(filter.Namespace, NamespaceSeparator); namespace != "" {
+		// Names in the namespace sort between "ns/" and "ns0", the next byte after the separator
+		prefix := namespace + NamespaceSeparator
+		where = append(where, `s.name >= ? AND s.name < ?`)
+		args = append(args, prefix, namespace+string(NamespaceSeparator[0]+1))
+	}
+	for _, tag := range filter.Tags {
+		where = append(where, `EXISTS (SELECT 1 FROM snippet_tags t WHERE t.snippet_id = s.id AND t.tag = ? COLLATE NOCASE)`)
+		args = append(args, tag)
+	}
+	if filter.Pinned {
+		where = append(where, `s.pinned = 1`)
+	}
+	if filter.Language != "" {
+		where = append(where, `s.language = ? COLLATE NOCASE`)
+		args = append(args, filter.Language)
+	}
+	if filter.Keyword != "" {
+		if utf8.RuneCountInString(filter.Keyword) >= 3 {
+			where = append(where, `s.id IN (SELECT rowid FROM snippets_fts WHERE snippets_fts MATCH ?)`)
+			args = append(args, `"`+strings.ReplaceAll(filter.Keyword, `"`, `""`)+`"`)
+		} else {
+			pattern := "%" + escapeLike(filter.Keyword) + "%"
+			where = append(where, `s.id IN (SELECT rowid FROM snippets_fts WHERE
+				name LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\' OR command LIKE ? ESCAPE '\' OR
+				tags LIKE ? ESCAPE '\' OR aliases LIKE ? ESCAPE '\')`)
+			args = append(args, pattern, pattern, pattern, pattern, pattern)
+		}
+	}
+
+	query := `SELECT s.name, s.data FROM snippets s`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	query += ` ORDER BY s.name`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snippets database: %w", err)
+	}
+	defer rows.Close()
+
+	snippets := []Snippet{}
+	for rows.Next() {
+		name, snippet, err := scanSnippet(rows)
+		if err != nil {
+			return nil, err
+		}
+		snippet.Name = name
+		snippets = append(snippets, snippet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query snippets database: %w", err)
+	}
+	return snippets, nil
+}
+
+// TagCounts counts the snippets per tag in SQL, most used first
+func (st *sqliteStore) TagCounts() ([]TagCount, error) {
+	db, err := st.open(false)
+	if err != nil || db == nil {
+		return []TagCount{}, err
+	}
+
+	rows, err := db.Query(`SELECT tag, COUNT(*) AS uses FROM snippet_tags GROUP BY tag ORDER BY uses DESC, tag`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snippets database: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []TagCount{}
+	for rows.Next() {
+		var tag TagCount
+		if err := rows.Scan(&tag.Tag, &tag.Count); err != nil {
+			return nil, fmt.Errorf("failed to query snippets database: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query snippets database: %w", err)
+	}
+	return tags, nil
+}
+
+// open returns the database, creating it only when create is set.
+// A database that does not exist yet is reported as nil without an error.
+func (st *sqliteStore) open(create bool) (*sql.DB, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.db != nil {
+		return st.db, nil
+	}
+	if _, err := os.Stat(st.path); os.IsNotExist(err) && !create {
+		return nil, nil
+	}
+
+	// Write transactions take the lock up front so concurrent writers wait instead of failing
+	dsn := st.path + "?_txlock=immediate" +
+		"&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snippets database: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize snippets database: %w", err)
+	}
+	if err := checkDatabaseVersion(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	writer, err := db.Conn(context.Background())
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open snippets database: %w", err)
+	}
+	if err := writer.QueryRowContext(context.Background(), `PRAGMA data_version`).Scan(&st.dataVersion); err != nil {
+		writer.Close()
+		db.Close()
+		return nil, fmt.Errorf("failed to read snippets database: %w", err)
+	}
+
+	st.db, st.writer = db, writer
+	return db, nil
+}
+
+// changedExternally reports whether another process committed to the database since the last call
+func (st *sqliteStore) changedExternally() (bool, error) {
+	db, err := st.open(false)
+	if err != nil || db == nil {
+		// Let Load report the error, or pick up a database created by another process
+		return true, nil
+	}
+
+	st.writeMu.Lock()
+	defer st.writeMu.Unlock()
+	var version int64
+	if err := st.writer.QueryRowContext(context.Background(), `PRAGMA data_version`).Scan(&version); err != nil {
+		return false, fmt.Errorf("failed to read snippets database: %w", err)
+	}
+	changed := version != st.dataVersion
+	st.dataVersion = version
+	return changed, nil
+}
+
+// checkDatabaseVersion rejects databases written by a newer version of sni
+func checkDatabaseVersion(db *sql.DB) error {
+	var value string
+	err := db.QueryRow(`SELECT value FROM meta WHERE key = 'version'`).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snippets database version: %w", err)
+	}
+
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid snippets database version '%s'", value)
+	}
+	if version > CurrentVersion {
+		return fmt.Errorf("snippets database version %d is newer than this sni supports (%d); upgrade sni to open it", version, CurrentVersion)
+	}
+	return nil
+}
+
+// storedRow is a snippet row as read back before a save
+type storedRow struct {
+	id   int64
+	data string
+}
+
+// insertSnippetRow stores a snippet with its tags and full-text entry
+func insertSnippetRow(tx *sql.Tx, name string, snippet Snippet, data string) error {
+	result, err := tx.Exec(`INSERT INTO snippets (name, language, pinned, data) VALUES (?, ?, ?, ?)`,
+		name, snippet.Language, snippet.Pinned, data)
+	if err != nil {
+		return fmt.Errorf("failed to write snippet '%s': %w", name, err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to write snippet '%s': %w", name, err)
+	}
+
+	for _, tag := range snippet.Tags {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO snippet_tags (snippet_id, tag) VALUES (?, ?)`, id, tag); err != nil {
+			return fmt.Errorf("failed to write tags of snippet '%s': %w", name, err)
+		}
+	}
+
+	// Secret commands are sealed and never searched
+	command := snippet.Command
+	if snippet.Secret {
+		command = ""
+	}
+	_, err = tx.Exec(`INSERT INTO snippets_fts (rowid, name, description, command, tags, aliases) VALUES (?, ?, ?, ?, ?, ?)`,
+		id, name, snippet.Description, command, strings.Join(snippet.Tags, "\n"), strings.Join(snippet.Aliases, "\n"))
+	if err != nil {
+		return fmt.Errorf("failed to index snippet '%s': %w", name, err)
+	}
+	return nil
+}
+
+// deleteSnippetRow removes a snippet with its tags and full-text entry
+func deleteSnippetRow(tx *sql.Tx, id int64) error {
+	if _, err := tx.Exec(`DELETE FROM snippets_fts WHERE rowid = ?`, id); err != nil {
+		return fmt.Errorf("failed to remove snippet from index: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM snippets WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to remove snippet: %w", err)
+	}
+	return nil
+}
+
+// scanSnippet decodes a name and YAML data row
+func scanSnippet(rows *sql.Rows) (string, Snippet, error) {
+	var name, data string
+	if err := rows.Scan(&name, &data); err != nil {
+		return "", Snippet{}, fmt.Errorf("failed to read snippets database: %w", err)
+	}
+	snippet, err := parseSnippetRow(name, data)
+	return name, snippet, err
+}
+
+// parseSnippetRow decodes the YAML data of a snippet row
+func parseSnippetRow(name, data string) (Snippet, error) {
+	var snippet Snippet
+	if err := yaml.Unmarshal([]byte(data), &snippet); err != nil {
+		return Snippet{}, fmt.Errorf("failed to parse snippet '%s' from database: %w", name, err)
+	}
+	return snippet, nil
+}
+
+// storedRows reads the ID and data of every snippet row inside a transaction
+func storedRows(tx *sql.Tx) (map[string]storedRow, error) {
+	rows, err := tx.Query(`SELECT id, name, data FROM snippets`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippets database: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]storedRow)
+	for rows.Next() {
+		var row storedRow
+		var name string
+		if err := rows.Scan(&row.id, &name, &row.data); err != nil {
+			return nil, fmt.Errorf("failed to read snippets database: %w", err)
+		}
+		existing[name] = row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read snippets database: %w", err)
+	}
+	return existing, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s for use with ESCAPE '\'
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
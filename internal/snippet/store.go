@@ -0,0 +1,228 @@
+package snippet
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atobaum/snippet-manager/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Storage backends selectable with storage.backend in config.yaml
+const (
+	StorageYAML   = "yaml"
+	StorageDir    = "dir"
+	StorageSQLite = "sqlite"
+)
+
+// StorageBackends lists the supported storage backends
+var StorageBackends = []string{StorageYAML, StorageDir, StorageSQLite}
+
+// Store persists the whole snippet collection
+type Store interface {
+	// Load reads every snippet; a store without data returns an empty collection
+	Load() (*SnippetsFile, error)
+	// Save replaces the stored collection with the given snippets as they are
+	Save(snippetsFile *SnippetsFile) error
+	// Snapshot returns the stored collection in snippets.yaml format, or nil if nothing is stored
+	Snapshot() ([]byte, error)
+}
+
+// queryStore is implemented by stores that filter and count snippets themselves
+// instead of loading the whole collection
+type queryStore interface {
+	// Query returns the snippets matching the filter, sorted by name
+	Query(filter Filter) ([]Snippet, error)
+	// TagCounts returns every tag in use, most used first
+	TagCounts() ([]TagCount, error)
+}
+
+// queries returns the query support of the configured store, looking through the in-memory cache
+func (s *Service) queries() (queryStore, bool) {
+	store := s.store
+	if cache, ok := store.(*cachedStore); ok {
+		store = cache.store
+	}
+	qs, ok := store.(queryStore)
+	return qs, ok
+}
+
+// NewStore returns the store for a storage backend
+func NewStore(cfg *config.Config, backend string) (Store, error) {
+	switch backend {
+	case "", StorageYAML:
+		return &yamlStore{path: cfg.SnippetFile}, nil
+	case StorageDir:
+		return &dirStore{dir: cfg.SnippetDir}, nil
+	case StorageSQLite:
+		return &sqliteStore{path: cfg.SnippetDB}, nil
+	}
+	return nil, fmt.Errorf("unknown storage backend '%s' (supported: %s)", backend, strings.Join(StorageBackends, ", "))
+}
+
+// yamlStore keeps every snippet in a single snippets.yaml file
+type yamlStore struct {
+	path string
+}
+
+// Load reads snippets.yaml. Files in an older format are upgraded in memory and copied to
+// snippets.yaml.v<version>.bak the first time they are read; the file itself is only
+// rewritten by the next save.
+func (st *yamlStore) Load() (*SnippetsFile, error) {
+	// If file doesn't exist, return empty snippets
+	if _, err := os.Stat(st.path); os.IsNotExist(err) {
+		return &SnippetsFile{
+			Version:  CurrentVersion,
+			Snippets: make(map[string]Snippet),
+		}, nil
+	}
+
+	data, err := os.ReadFile(st.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippets file: %w", err)
+	}
+
+	snippetsFile, version, err := parseVersioned(data)
+	if err != nil {
+		return nil, err
+	}
+	if version < CurrentVersion {
+		// Best effort, so read-only sessions still work; Save writes the backup again before upgrading
+		st.writeOutdatedBackup(version, data, false)
+	}
+	return snippetsFile, nil
+}
+
+// Save writes snippets.yaml in the current format version.
+// A file in an older format is first kept as snippets.yaml.v<version>.bak.
+func (st *yamlStore) Save(snippetsFile *SnippetsFile) error {
+	snippetsFile.Version = CurrentVersion
+
+	if err := st.backupOutdated(); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(snippetsFile)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snippets: %w", err)
+	}
+
+	if err := writeFileAtomic(st.path, data); err != nil {
+		return fmt.Errorf("failed to write snippets file: %w", err)
+	}
+
+	return nil
+}
+
+// Snapshot returns the contents of snippets.yaml as stored
+func (st *yamlStore) Snapshot() ([]byte, error) {
+	data, err := os.ReadFile(st.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippets file: %w", err)
+	}
+	return data, nil
+}
+
+// storedVersion returns the format version of snippets.yaml, or CurrentVersion if there is no file
+func (st *yamlStore) storedVersion() (int, []byte, error) {
+	data, err := os.ReadFile(st.path)
+	if os.IsNotExist(err) {
+		return CurrentVersion, nil, nil
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read snippets file: %w", err)
+	}
+	version, err := fileVersion(data)
+	return version, data, err
+}
+
+// backupOutdated backs up a snippets file written in an older version before it is overwritten
+func (st *yamlStore) backupOutdated() error {
+	version, data, err := st.storedVersion()
+	if err != nil || version >= CurrentVersion {
+		// A file that does not parse is covered by the regular backups
+		return nil
+	}
+	return st.writeOutdatedBackup(version, data, true)
+}
+
+// writeOutdatedBackup copies snippets file data of an older version to snippets.yaml.v<version>.bak.
+// An existing backup is only replaced with overwrite.
+func (st *yamlStore) writeOutdatedBackup(version int, data []byte, overwrite bool) error {
+	backup := fmt.Sprintf("%s.v%d.bak", st.path, version)
+	if !overwrite {
+		if _, err := os.Stat(backup); err == nil {
+			return nil
+		}
+	}
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		return fmt.Errorf("failed to back up snippets file before upgrading: %w", err)
+	}
+	return nil
+}
+
+// MigrateStorage copies every snippet to the store for backend, checks that the copy reads back
+// identically and records the backend in config.yaml. The previous store is left in place.
+// A target that already holds snippets is only overwritten with force, after it is backed up.
+func (s *Service) MigrateStorage(backend string, force bool) (int, error) {
+	current := s.config.Storage.Backend
+	if current == "" {
+		current = StorageYAML
+	}
+	if backend == current {
+		return 0, fmt.Errorf("snippets are already stored with the %s backend", backend)
+	}
+
+	target, err := NewStore(s.config, backend)
+	if err != nil {
+		return 0, err
+	}
+
+	snippetsFile, err := s.store.Load()
+	if err != nil {
+		return 0, err
+	}
+	existing, err := target.Load()
+	if err != nil {
+		return 0, err
+	}
+	if len(existing.Snippets) > 0 {
+		if !force {
+			return 0, fmt.Errorf("the %s store already holds %d snippet(s); use --force to replace them", backend, len(existing.Snippets))
+		}
+		if err := s.backupStore(target); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := target.Save(snippetsFile); err != nil {
+		return 0, err
+	}
+
+	copied, err := target.Load()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrated snippets: %w", err)
+	}
+	want, err := yaml.Marshal(snippetsFile)
+	if err != nil {
+		return 0, err
+	}
+	got, err := yaml.Marshal(copied)
+	if err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(want, got) {
+		return 0, fmt.Errorf("migrated snippets differ from the original; storage backend left unchanged")
+	}
+
+	if err := s.config.SetStorageBackend(backend); err != nil {
+		return 0, err
+	}
+	s.store = target
+	return len(snippetsFile.Snippets), nil
+}
@@ -0,0 +1,172 @@
+package snippet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NormalizeTag trims and lowercases a tag
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags normalizes each tag, dropping empty and duplicate tags.
+// A nil slice stays nil so callers can tell "no change" from "no tags".
+func NormalizeTags(tags []string) []string {
+	return normalizeTags(tags, nil)
+}
+
+// tagSynonyms maps lowercase synonyms to their canonical tag,
+// from the tags.synonyms setting given as canonical tag to the tags it replaces
+func tagSynonyms(synonyms map[string][]string) map[string]string {
+	mapped := make(map[string]string)
+	for canonical, others := range synonyms {
+		canonical = NormalizeTag(canonical)
+		for _, other := range others {
+			mapped[NormalizeTag(other)] = canonical
+		}
+	}
+	return mapped
+}
+
+// NormalizeTag normalizes a tag and replaces a configured synonym with its canonical tag
+func (s *Service) NormalizeTag(tag string) string {
+	tag = NormalizeTag(tag)
+	if canonical, ok := s.tagSynonyms[tag]; ok {
+		return canonical
+	}
+	return tag
+}
+
+// NormalizeTags normalizes each tag like NormalizeTags, also replacing configured synonyms
+func (s *Service) NormalizeTags(tags []string) []string {
+	return normalizeTags(tags, s.tagSynonyms)
+}
+
+// normalizeTags normalizes tags, replaces synonyms and drops empty and duplicate tags
+func normalizeTags(tags []string, synonyms map[string]string) []string {
+	if tags == nil {
+		return nil
+	}
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if canonical, ok := synonyms[tag]; ok {
+			tag = canonical
+		}
+		if tag != "" && !containsString(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// TagCount is a tag with the number of snippets using it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// TagCounts returns every tag in use, most used first
+func (s *Service) TagCounts() ([]TagCount, error) {
+	if qs, ok := s.queries(); ok {
+		return qs.TagCounts()
+	}
+
+	snippetsFile, err := s.LoadSnippets()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, snippet := range snippetsFile.Snippets {
+		for _, tag := range snippet.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags, nil
+}
+
+// RenameTag renames a tag on every snippet and returns the number of snippets changed.
+// It fails if the new tag is already in use; use MergeTags to combine tags.
+func (s *Service) RenameTag(oldTag, newTag string) (int, error) {
+	counts, err := s.TagCounts()
+	if err != nil {
+		return 0, err
+	}
+	target := s.NormalizeTag(newTag)
+	for _, tc := range counts {
+		if tc.Tag == target && !strings.EqualFold(tc.Tag, oldTag) {
+			return 0, fmt.Errorf("tag '%s' already exists; use merge to combine tags", target)
+		}
+	}
+	return s.MergeTags([]string{oldTag}, newTag)
+}
+
+// MergeTags replaces the source tags with the target tag on every snippet
+// and returns the number of snippets changed
+func (s *Service) MergeTags(sources []string, target string) (int, error) {
+	target = s.NormalizeTag(target)
+	if target == "" {
+		return 0, fmt.Errorf("target tag is required")
+	}
+	return s.replaceTags(sources, target)
+}
+
+// RemoveTag removes a tag from every snippet and returns the number of snippets changed
+func (s *Service) RemoveTag(tag string) (int, error) {
+	return s.replaceTags([]string{tag}, "")
+}
+
+// replaceTags replaces the given tags with replacement, or removes them if it is empty
+func (s *Service) replaceTags(tags []string, replacement string) (int, error) {
+	snippetsFile, err := s.LoadSnippets()
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	now := time.Now()
+	for name, snippet := range snippetsFile.Snippets {
+		var updated []string
+		found := false
+		for _, tag := range snippet.Tags {
+			if hasTag(tags, tag) {
+				found = true
+				tag = replacement
+			}
+			if tag != "" && !containsString(updated, tag) {
+				updated = append(updated, tag)
+			}
+		}
+		if !found {
+			continue
+		}
+
+		if updated == nil {
+			updated = []string{}
+		}
+		snippet.Tags = updated
+		snippet.UpdatedAt = now
+		snippetsFile.Snippets[name] = snippet
+		changed++
+	}
+
+	if changed == 0 {
+		return 0, fmt.Errorf("no snippet has tag '%s'", strings.Join(tags, "', '"))
+	}
+	return changed, s.SaveSnippets(snippetsFile)
+}
@@ -0,0 +1,161 @@
+package snippet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the snippets file format version written by this build.
+// Files without a version key are version 0.
+const CurrentVersion = 1
+
+// migration upgrades a snippets document by one version. It works on the YAML nodes
+// so values keep the text they were written with.
+type migration func(doc *yaml.Node) error
+
+// migrations[i] upgrades a document from version i to i+1
+var migrations = []migration{
+	lowercaseTags,
+}
+
+// ParseSnippetsFile parses the contents of a snippets.yaml file, upgrading older formats in memory.
+// Files written by a newer version of sni are rejected.
+func ParseSnippetsFile(data []byte) (*SnippetsFile, error) {
+	snippetsFile, _, err := parseVersioned(data)
+	return snippetsFile, err
+}
+
+// parseVersioned parses a snippets file and returns the format version it was written in
+func parseVersioned(data []byte) (*SnippetsFile, int, error) {
+	version, err := fileVersion(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if version > CurrentVersion {
+		return nil, version, fmt.Errorf("snippets file version %d is newer than this sni supports (%d); upgrade sni to open it", version, CurrentVersion)
+	}
+	if version < 0 {
+		return nil, version, fmt.Errorf("invalid snippets file version %d", version)
+	}
+
+	if version < CurrentVersion {
+		migrated, err := migrate(data, version)
+		if err != nil {
+			return nil, version, err
+		}
+		data = migrated
+	}
+
+	var snippetsFile SnippetsFile
+	if err := yaml.Unmarshal(data, &snippetsFile); err != nil {
+		return nil, version, fmt.Errorf("failed to parse snippets file: %w", err)
+	}
+
+	// Initialize map if nil
+	if snippetsFile.Snippets == nil {
+		snippetsFile.Snippets = make(map[string]Snippet)
+	}
+	snippetsFile.Version = CurrentVersion
+
+	return &snippetsFile, version, nil
+}
+
+// fileVersion reads the format version of a snippets file without parsing the snippets
+func fileVersion(data []byte) (int, error) {
+	var header struct {
+		Version int `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return 0, fmt.Errorf("failed to parse snippets file: %w", err)
+	}
+	return header.Version, nil
+}
+
+// migrate upgrades raw snippets file data from the given version to CurrentVersion one step at a time
+func migrate(data []byte, from int) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse snippets file: %w", err)
+	}
+	if root.Kind == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("failed to parse snippets file: line %d: expected a mapping", doc.Line)
+	}
+
+	for version := from; version < CurrentVersion; version++ {
+		if err := migrations[version](doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate snippets file from version %d to %d: %w", version, version+1, err)
+		}
+	}
+	setMappingValue(doc, "version", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(CurrentVersion)})
+
+	return yaml.Marshal(&root)
+}
+
+// lowercaseTags upgrades version 0 files, whose tags were stored as typed, to trimmed lowercase tags.
+// Tags YAML would read as numbers, booleans or dates, such as 2024, are kept as written.
+func lowercaseTags(doc *yaml.Node) error {
+	snippets := mappingValue(doc, "snippets")
+	if snippets == nil || snippets.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(snippets.Content); i += 2 {
+		name, snippet := snippets.Content[i].Value, snippets.Content[i+1]
+		if snippet.Kind != yaml.MappingNode {
+			continue
+		}
+		tags := mappingValue(snippet, "tags")
+		if tags == nil || tags.Kind != yaml.SequenceNode {
+			continue
+		}
+
+		normalized := make([]*yaml.Node, 0, len(tags.Content))
+		seen := make(map[string]bool)
+		for _, tag := range tags.Content {
+			if tag.Kind == yaml.AliasNode {
+				tag = tag.Alias
+			}
+			if tag.Kind != yaml.ScalarNode {
+				return fmt.Errorf("snippet '%s' has a tag that is not a string (line %d)", name, tag.Line)
+			}
+			if tag.Tag == "!!null" {
+				continue
+			}
+			text := strings.ToLower(strings.TrimSpace(tag.Value))
+			if text != "" && !seen[text] {
+				seen[text] = true
+				normalized = append(normalized, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: text})
+			}
+		}
+		tags.Content = normalized
+	}
+	return nil
+}
+
+// mappingValue returns the value node of a key in a mapping node, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue replaces the value of a key in a mapping node, adding the key first if it is missing
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	mapping.Content = append([]*yaml.Node{keyNode, value}, mapping.Content...)
+}
@@ -0,0 +1,190 @@
+package snippet
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVersioned(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantVersion int
+		wantTags    map[string][]string
+		wantErr     string
+	}{
+		{
+			name:        "empty file",
+			data:        "",
+			wantVersion: 0,
+			wantTags:    map[string][]string{},
+		},
+		{
+			name: "version 0 tags are trimmed, lowercased and deduplicated",
+			data: `snippets:
+  deploy:
+    command: make deploy
+    tags: [" Ops ", OPS, Make, ""]
+`,
+			wantVersion: 0,
+			wantTags:    map[string][]string{"deploy": {"ops", "make"}},
+		},
+		{
+			name: "version 0 tags that read as other types keep their text",
+			data: `snippets:
+  release:
+    command: make release
+    tags: [2024, 1.50, 2024-01-02, True, ~]
+`,
+			wantVersion: 0,
+			wantTags:    map[string][]string{"release": {"2024", "1.50", "2024-01-02", "true"}},
+		},
+		{
+			name: "version 0 tags through aliases",
+			data: `snippets:
+  a:
+    command: a
+    tags: [&shared Shared]
+  b:
+    command: b
+    tags: [*shared, B]
+`,
+			wantVersion: 0,
+			wantTags:    map[string][]string{"a": {"shared"}, "b": {"shared", "b"}},
+		},
+		{
+			name: "version 0 snippet without tags",
+			data: `snippets:
+  plain:
+    command: echo
+`,
+			wantVersion: 0,
+			wantTags:    map[string][]string{"plain": nil},
+		},
+		{
+			name: "version 0 tag that is not a string",
+			data: `snippets:
+  broken:
+    command: echo
+    tags:
+      - {name: ops}
+`,
+			wantErr: "snippet 'broken' has a tag that is not a string (line 5)",
+		},
+		{
+			name: "current version is not migrated",
+			data: `version: 1
+snippets:
+  deploy:
+    command: make deploy
+    tags: [Ops]
+`,
+			wantVersion: 1,
+			wantTags:    map[string][]string{"deploy": {"Ops"}},
+		},
+		{
+			name:    "newer version",
+			data:    "version: 2\nsnippets: {}\n",
+			wantErr: "newer than this sni supports",
+		},
+		{
+			name:    "negative version",
+			data:    "version: -1\n",
+			wantErr: "invalid snippets file version -1",
+		},
+		{
+			name:    "not a mapping",
+			data:    "- deploy\n",
+			wantErr: "failed to parse snippets file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snippetsFile, version, err := parseVersioned([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseVersioned() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseVersioned() unexpected error: %v", err)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("parseVersioned() version = %d, want %d", version, tt.wantVersion)
+			}
+			if snippetsFile.Version != CurrentVersion {
+				t.Errorf("parsed file version = %d, want %d", snippetsFile.Version, CurrentVersion)
+			}
+
+			tags := make(map[string][]string, len(snippetsFile.Snippets))
+			for name, snippet := range snippetsFile.Snippets {
+				tags[name] = snippet.Tags
+			}
+			if !reflect.DeepEqual(tags, tt.wantTags) {
+				t.Errorf("tags = %q, want %q", tags, tt.wantTags)
+			}
+		})
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "adds the version and keeps other values as written",
+			data: `snippets:
+  deploy:
+    description: "yes"
+    command: make deploy
+    tags: [Ops, 2024]
+`,
+			want: `version: 1
+snippets:
+    deploy:
+        description: "yes"
+        command: make deploy
+        tags: [ops, "2024"]
+`,
+		},
+		{
+			name: "replaces an explicit version 0",
+			data: "version: 0\nsnippets: {}\n",
+			want: "version: 1\nsnippets: {}\n",
+		},
+		{
+			name: "empty document",
+			data: "",
+			want: "version: 1\n",
+		},
+		{
+			name:    "document that is not a mapping",
+			data:    "just text\n",
+			wantErr: "line 1: expected a mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := migrate([]byte(tt.data), 0)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("migrate() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("migrate() unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("migrate() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package snippet
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay groups the burst of events an editor or git produces into a single reload
+const reloadDelay = 200 * time.Millisecond
+
+// watchedStore is implemented by stores whose data can change on disk behind sni's back
+type watchedStore interface {
+	// watchDirs returns the directories to watch for changes
+	watchDirs() []string
+	// ownsPath reports whether a changed path belongs to the stored data
+	ownsPath(path string) bool
+}
+
+// changeDetector is implemented by stores that can cheaply tell whether another process saved.
+// Watching skips the reload when the file events came from sni's own saves.
+type changeDetector interface {
+	// changedExternally reports whether another process saved since the last call
+	changedExternally() (bool, error)
+}
+
+// cachedStore keeps the last collection that loaded successfully in memory.
+// Reload replaces it only when the store loads without error, so a broken edit
+// leaves the previous snippets in service. Every change to the cached snippets
+// is published as events.
+type cachedStore struct {
+	store  Store
+	events *eventLog
+
+	mu        sync.Mutex
+	cached    *SnippetsFile
+	revisions map[string]string
+}
+
+// Load returns a copy of the cached snippets, loading them on first use
+func (c *cachedStore) Load() (*SnippetsFile, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached == nil {
+		snippetsFile, err := c.store.Load()
+		if err != nil {
+			return nil, err
+		}
+		c.cached = snippetsFile
+		c.revisions = revisions(snippetsFile)
+	}
+	return c.cached.clone(), nil
+}
+
+// Save writes through to the store and caches what was written
+func (c *cachedStore) Save(snippetsFile *SnippetsFile) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.store.Save(snippetsFile); err != nil {
+		return err
+	}
+	c.swap(snippetsFile.clone())
+	return nil
+}
+
+// Snapshot reads the stored data directly
+func (c *cachedStore) Snapshot() ([]byte, error) {
+	return c.store.Snapshot()
+}
+
+// Reload loads the store again and swaps in the result if it is valid.
+// It reports whether the snippets differ from the cached ones.
+func (c *cachedStore) Reload() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snippetsFile, err := c.store.Load()
+	if err != nil {
+		return false, err
+	}
+	return c.swap(snippetsFile), nil
+}
+
+// swap caches a new collection and publishes the snippets that changed, reporting whether any did
+func (c *cachedStore) swap(snippetsFile *SnippetsFile) bool {
+	revs := revisions(snippetsFile)
+	events := diffRevisions(c.revisions, revs)
+	c.cached, c.revisions = snippetsFile, revs
+	if len(events) > 0 && c.events != nil {
+		c.events.publish(events)
+	}
+	return len(events) > 0
+}
+
+// Watch caches the snippets in memory and reloads them whenever the stored data changes on disk,
+// e.g. after an edit in a text editor or a git pull, and publishes the changes to subscribers
+// (see Subscribe). report is called with nil after a reload
+// that changed the snippets, or with the error that kept the previous snippets in place.
+// Watching stops when done is closed.
+func (s *Service) Watch(done <-chan struct{}, report func(error)) error {
+	watched, ok := s.store.(watchedStore)
+	if !ok {
+		return fmt.Errorf("the %s storage backend cannot be watched", s.config.Storage.Backend)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start file watcher: %w", err)
+	}
+	for _, dir := range watched.watchDirs() {
+		if err := addWatchTree(watcher, dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+
+	events := newEventLog()
+	cache := &cachedStore{store: s.store, events: events}
+	if _, err := cache.Load(); err != nil {
+		watcher.Close()
+		return err
+	}
+	s.store, s.events = cache, events
+
+	go func() {
+		defer watcher.Close()
+
+		timer := time.NewTimer(reloadDelay)
+		timer.Stop()
+		for {
+			select {
+			case <-done:
+				timer.Stop()
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !watched.ownsPath(event.Name) {
+					continue
+				}
+				// New namespace directories must be watched as well
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						addWatchTree(watcher, event.Name)
+					}
+				}
+				timer.Reset(reloadDelay)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				report(fmt.Errorf("file watcher: %w", err))
+			case <-timer.C:
+				if detector, ok := watched.(changeDetector); ok {
+					if changed, err := detector.changedExternally(); err != nil {
+						report(err)
+						continue
+					} else if !changed {
+						continue
+					}
+				}
+				if changed, err := cache.Reload(); changed || err != nil {
+					report(err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// addWatchTree watches dir and, for the dir store, every directory below it
+func addWatchTree(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// watchDirs watches the directory holding snippets.yaml, since editors and git replace the file
+func (st *yamlStore) watchDirs() []string {
+	return []string{filepath.Dir(st.path)}
+}
+
+// ownsPath matches snippets.yaml itself
+func (st *yamlStore) ownsPath(path string) bool {
+	return path == st.path
+}
+
+// watchDirs watches the snippets directory tree, or its parent until the directory is created
+func (st *dirStore) watchDirs() []string {
+	if _, err := os.Stat(st.dir); err != nil {
+		return []string{filepath.Dir(st.dir)}
+	}
+	return []string{filepath.Dir(st.dir), st.dir}
+}
+
+// ownsPath matches the snippets directory and the files below it, except hidden and temporary files
+func (st *dirStore) ownsPath(path string) bool {
+	if path != st.dir && !strings.HasPrefix(path, st.dir+string(filepath.Separator)) {
+		return false
+	}
+	return !strings.HasPrefix(filepath.Base(path), ".") || filepath.Base(path) == dirVersionFile
+}
+
+// watchDirs watches the directory holding the database and its write-ahead log
+func (st *sqliteStore) watchDirs() []string {
+	return []string{filepath.Dir(st.path)}
+}
+
+// ownsPath matches the database and its write-ahead log, which takes every commit in WAL mode.
+// The server's own commits touch the log too; changedExternally filters them out.
+func (st *sqliteStore) ownsPath(path string) bool {
+	return path == st.path || path == st.path+"-wal"
+}
+
+// clone returns a copy of the collection that shares no slices or maps with the original
+func (f *SnippetsFile) clone() *SnippetsFile {
+	copied := &SnippetsFile{
+		Version:  f.Version,
+		Snippets: make(map[string]Snippet, len(f.Snippets)),
+	}
+	if f.loaded != nil {
+		copied.loaded = make(map[string]string, len(f.loaded))
+		for name, data := range f.loaded {
+			copied.loaded[name] = data
+		}
+	}
+	for name, snippet := range f.Snippets {
+		snippet.Tags = cloneSlice(snippet.Tags)
+		snippet.Aliases = cloneSlice(snippet.Aliases)
+		snippet.Files = cloneSlice(snippet.Files)
+		snippet.Hooks = cloneSlice(snippet.Hooks)
+		snippet.Placeholders = cloneSlice(snippet.Placeholders)
+		copied.Snippets[name] = snippet
+	}
+	return copied
+}
+
+// cloneSlice copies a slice, keeping nil and empty slices apart so the YAML output is unchanged
+func cloneSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}